@@ -0,0 +1,182 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"cuboid-challenge/app/store"
+)
+
+var (
+	ErrInvalidLimit  = errors.New("invalid limit")
+	ErrInvalidSort   = errors.New("invalid sort")
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidFilter = errors.New("invalid filter")
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 500
+)
+
+// ListParams are the parameters of a list request, as the transport reads them. Limit is the default
+// one when nil, Sort is a key, `-` prefixed for descending order, and Cursor is the one of the previous
+// page. The filters are the text of their value by name, the ones the list does not know are ignored.
+type ListParams struct {
+	Limit          *int
+	Cursor         string
+	Sort           string
+	IncludeDeleted bool
+	Filters        map[string]string
+}
+
+// listFilter parses the value of the filter it is named after.
+type listFilter func(string) (interface{}, error)
+
+func uintFilter(v string) (interface{}, error) { return strconv.ParseUint(v, 10, 64) }
+
+func boolFilter(v string) (interface{}, error) { return strconv.ParseBool(v) }
+
+// ListSpec declares the sort keys and filters a list accepts, the store knows what they stand for.
+type ListSpec struct {
+	sorts   []string
+	filters map[string]listFilter
+}
+
+// BagList and CuboidList are the lists of bags and cuboids. The volume filters are in the canonical unit.
+var (
+	BagList = ListSpec{
+		sorts: []string{"id", "title", "volume", "payloadVolume", "availableVolume"},
+		filters: map[string]listFilter{
+			"disabled":           boolFilter,
+			"minVolume":          uintFilter,
+			"maxVolume":          uintFilter,
+			"minAvailableVolume": uintFilter,
+			"maxAvailableVolume": uintFilter,
+		},
+	}
+
+	CuboidList = ListSpec{
+		sorts: []string{"id", "width", "height", "depth", "volume", "bagId"},
+		filters: map[string]listFilter{
+			"bagId":     uintFilter,
+			"disabled":  boolFilter,
+			"minVolume": uintFilter,
+			"maxVolume": uintFilter,
+		},
+	}
+)
+
+func (s ListSpec) sortable(key string) bool {
+	for _, sort := range s.sorts {
+		if sort == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+// listCursor points right after the last record of a page, for the sort it was issued with.
+type listCursor struct {
+	Sort  string      `json:"s"`
+	Value interface{} `json:"v"`
+	ID    uint        `json:"id"`
+}
+
+// Parse checks the parameters against the list and returns the query of the page they ask for.
+func (s ListSpec) Parse(params ListParams) (store.ListQuery, error) {
+	sort := params.Sort
+	if sort == "" {
+		sort = "id"
+	}
+
+	q := store.ListQuery{
+		Limit:          defaultListLimit,
+		Sort:           strings.TrimPrefix(sort, "-"),
+		Desc:           strings.HasPrefix(sort, "-"),
+		Filters:        map[string]interface{}{},
+		IncludeDeleted: params.IncludeDeleted,
+	}
+
+	if params.Limit != nil {
+		if *params.Limit < 1 || *params.Limit > maxListLimit {
+			return q, fmt.Errorf("%w, it must be between 1 and %d", ErrInvalidLimit, maxListLimit)
+		}
+		q.Limit = *params.Limit
+	}
+
+	if !s.sortable(q.Sort) {
+		return q, fmt.Errorf("%w key %q", ErrInvalidSort, q.Sort)
+	}
+
+	if params.Cursor != "" {
+		cursor, err := decodeCursor(params.Cursor)
+		if err != nil || cursor.Sort != sort {
+			return q, ErrInvalidCursor
+		}
+		q.After = &store.Cursor{Value: cursor.Value, ID: cursor.ID}
+	}
+
+	for name, parse := range s.filters {
+		v, ok := params.Filters[name]
+		if !ok {
+			continue
+		}
+
+		value, err := parse(v)
+		if err != nil {
+			return q, fmt.Errorf("%w %s", ErrInvalidFilter, name)
+		}
+		q.Filters[name] = value
+	}
+
+	return q, nil
+}
+
+// EncodeCursor returns the cursor the client sends back for the page after next, empty on the last page.
+func EncodeCursor(q store.ListQuery, next *store.Cursor) (string, error) {
+	if next == nil {
+		return "", nil
+	}
+
+	sort := q.Sort
+	if q.Desc {
+		sort = "-" + sort
+	}
+
+	j, err := json.Marshal(listCursor{Sort: sort, Value: next.Value, ID: next.ID})
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(j), nil
+}
+
+func decodeCursor(s string) (listCursor, error) {
+	var cursor listCursor
+
+	j, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor, err
+	}
+
+	d := json.NewDecoder(strings.NewReader(string(j)))
+	d.UseNumber()
+	if err := d.Decode(&cursor); err != nil {
+		return cursor, err
+	}
+
+	// numbers have to reach the driver as integers to compare with the columns
+	if n, ok := cursor.Value.(json.Number); ok {
+		if cursor.Value, err = n.Int64(); err != nil {
+			return cursor, err
+		}
+	}
+
+	return cursor, nil
+}
@@ -0,0 +1,118 @@
+// Package api holds what the REST, the gRPC and the GraphQL APIs share whatever the transport: the
+// problems the errors are answered with, and the list queries with their cursors.
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"cuboid-challenge/app/inventory"
+	"cuboid-challenge/app/models"
+	"cuboid-challenge/app/store"
+	"cuboid-challenge/app/units"
+)
+
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrInvalidBody  = errors.New("invalid request body")
+	ErrBodyTooLarge = errors.New("request body too large")
+	ErrInvalidQuery = errors.New("invalid query parameters")
+	ErrInvalidPatch = errors.New("invalid merge patch")
+
+	ErrUnknownStrategy = errors.New("unknown packing strategy")
+	ErrInvalidBulkSize = errors.New("invalid number of bulk items")
+	ErrBulkFailed      = errors.New("bulk operation failed")
+
+	ErrInvalidIdempotencyKey = errors.New("invalid Idempotency-Key")
+	ErrKeyReused             = errors.New("idempotency key reused with a different request")
+	ErrKeyInProgress         = errors.New("a request with the idempotency key is in progress")
+)
+
+// ProblemType is a kind of error of the API, answered with an RFC 7807 problem document by the REST API.
+// Code is stable, clients should branch on it rather than on the title or the detail. Status is the
+// HTTP status, the other transports map it to their own codes.
+type ProblemType struct {
+	Code   string `json:"code"`
+	Status int    `json:"status"`
+	Title  string `json:"title"`
+	errs   []error
+}
+
+// URI is the `type` member of the problem documents.
+func (t ProblemType) URI() string {
+	return "/problems/" + t.Code
+}
+
+var (
+	validationProblem = ProblemType{"validation_failed", http.StatusBadRequest, "Validation failed", nil}
+	internalProblem   = ProblemType{"internal", http.StatusInternalServerError, "Internal Server Error", nil}
+)
+
+// ProblemTypes lists every documented problem with the errors that lead to it. They are served at /problems.
+var ProblemTypes = []ProblemType{
+	validationProblem,
+	{"invalid_body", http.StatusBadRequest, "Invalid request body", []error{ErrInvalidBody}},
+	{"body_too_large", http.StatusBadRequest, "Request body too large", []error{ErrBodyTooLarge}},
+	{"invalid_query", http.StatusBadRequest, "Invalid query parameters", []error{ErrInvalidQuery}},
+	{"not_found", http.StatusNotFound, "Not Found", []error{ErrNotFound, inventory.ErrCuboidNotFound, store.ErrNotFound}},
+	{"bag_not_found", http.StatusNotFound, "Bag Not Found", []error{inventory.ErrBagNotFound}},
+	{"insufficient_capacity", http.StatusBadRequest, "Insufficient capacity in bag", []error{inventory.ErrInsufficientCapacity}},
+	{"bag_disabled", http.StatusBadRequest, "Bag is disabled", []error{inventory.ErrBagIsDisabled}},
+	{"no_room_in_bag", http.StatusBadRequest, "No room in bag for the cuboid", []error{inventory.ErrNoRoomInBag}},
+	{"version_mismatch", http.StatusPreconditionFailed, "Precondition Failed", []error{inventory.ErrVersionMismatch}},
+	{"volume_below_payload", http.StatusBadRequest, "Volume is smaller than the bag payload", []error{inventory.ErrVolumeBelowPayload}},
+	{"bag_not_empty", http.StatusConflict, "Bag is not empty", []error{inventory.ErrBagNotEmpty}},
+	{"unknown_delete_policy", http.StatusBadRequest, "Unknown cuboids policy", []error{inventory.ErrUnknownDeletePolicy}},
+	{"invalid_move_target", http.StatusBadRequest, "Invalid target bag to move the cuboids to", []error{inventory.ErrInvalidMoveTarget}},
+	{"move_target_not_found", http.StatusNotFound, "Target Bag Not Found", []error{inventory.ErrMoveTargetNotFound}},
+	{"not_deleted", http.StatusConflict, "Record is not deleted", []error{inventory.ErrNotDeleted}},
+	{"concurrent_update", http.StatusConflict, "Concurrent update of the cuboid", []error{inventory.ErrConcurrentUpdate}},
+	{"invalid_patch", http.StatusBadRequest, "Invalid merge patch", []error{ErrInvalidPatch}},
+	{"invalid_limit", http.StatusBadRequest, "Invalid limit", []error{ErrInvalidLimit}},
+	{"invalid_sort", http.StatusBadRequest, "Invalid sort", []error{ErrInvalidSort}},
+	{"invalid_cursor", http.StatusBadRequest, "Invalid cursor", []error{ErrInvalidCursor}},
+	{"invalid_filter", http.StatusBadRequest, "Invalid filter", []error{ErrInvalidFilter}},
+	{"unknown_unit", http.StatusBadRequest, "Unknown unit", []error{units.ErrUnknownUnit}},
+	{"unknown_strategy", http.StatusBadRequest, "Unknown packing strategy", []error{ErrUnknownStrategy}},
+	{"invalid_bulk_size", http.StatusBadRequest, "Invalid number of bulk items", []error{ErrInvalidBulkSize}},
+	{"bulk_failed", http.StatusBadRequest, "Bulk operation failed", []error{ErrBulkFailed}},
+	{"invalid_idempotency_key", http.StatusBadRequest, "Invalid Idempotency-Key", []error{ErrInvalidIdempotencyKey}},
+	{"idempotency_key_reused", http.StatusUnprocessableEntity, "Idempotency-Key was used for a different request", []error{ErrKeyReused}},
+	{"idempotency_key_in_progress", http.StatusConflict, "A request with this Idempotency-Key is in progress", []error{ErrKeyInProgress}},
+	internalProblem,
+}
+
+// ProblemFor finds the problem type of err and the detail to show. The detail is the title, unless err
+// wraps the error of the type to tell more.
+func ProblemFor(err error) (ProblemType, string) {
+	var valErr models.ValidationErrors
+	if errors.As(err, &valErr) {
+		return validationProblem, valErr.Error()
+	}
+
+	for _, t := range ProblemTypes {
+		for _, e := range t.errs {
+			if !errors.Is(err, e) {
+				continue
+			}
+
+			if err == e {
+				return t, t.Title
+			}
+
+			return t, Capitalize(err.Error())
+		}
+	}
+
+	return internalProblem, err.Error()
+}
+
+// Capitalize upper cases the first letter of an error message to make it a detail.
+func Capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+
+	return strings.ToUpper(s[:1]) + s[1:]
+}
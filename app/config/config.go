@@ -6,14 +6,35 @@ import (
 	"log"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 type env struct {
-	Port     string `mapstructure:"PORT"`
+	Port string `mapstructure:"PORT"`
+	// GRPCPort is the port of the gRPC API, served alongside the REST one.
+	GRPCPort string `mapstructure:"GRPC_PORT"`
 	DBDriver string `mapstructure:"DB_DRIVER"`
-	DBName   string `mapstructure:"DB_NAME"`
+	// DBName is the SQLite database file.
+	DBName string `mapstructure:"DB_NAME"`
+	// DBDSN is the connection string of the postgres and mysql drivers.
+	DBDSN string `mapstructure:"DB_DSN"`
+
+	// DBMaxOpenConns, DBMaxIdleConns and DBConnMaxLifetime size the connection pool, zero keeps the
+	// database/sql defaults.
+	DBMaxOpenConns    int           `mapstructure:"DB_MAX_OPEN_CONNS"`
+	DBMaxIdleConns    int           `mapstructure:"DB_MAX_IDLE_CONNS"`
+	DBConnMaxLifetime time.Duration `mapstructure:"DB_CONN_MAX_LIFETIME"`
+
+	// IdempotencyWindow is how long the response to a request with an Idempotency-Key is replayed.
+	IdempotencyWindow time.Duration `mapstructure:"IDEMPOTENCY_WINDOW"`
+
+	// MaxBodyBytes is the size limit of request bodies.
+	MaxBodyBytes int64 `mapstructure:"MAX_BODY_BYTES"`
+	// RejectUnknownFields makes request bodies with fields the endpoint does not take invalid. It is off by
+	// default, clients send back the read only fields, like the volume, of the records they update.
+	RejectUnknownFields bool `mapstructure:"REJECT_UNKNOWN_FIELDS"`
 }
 
 // ENV is a mapper to the environmets variables.
@@ -26,6 +47,15 @@ func Load() {
 	viper.SetConfigFile(fmt.Sprintf("%s.env", env))
 	viper.AddConfigPath(".")
 	viper.AutomaticEnv()
+	// the keys need a default for the environment to override them when the file leaves them out
+	viper.SetDefault("GRPC_PORT", "9090")
+	viper.SetDefault("DB_DSN", "")
+	viper.SetDefault("DB_MAX_OPEN_CONNS", 0)
+	viper.SetDefault("DB_MAX_IDLE_CONNS", 0)
+	viper.SetDefault("DB_CONN_MAX_LIFETIME", 0)
+	viper.SetDefault("IDEMPOTENCY_WINDOW", "24h")
+	viper.SetDefault("MAX_BODY_BYTES", 1<<20)
+	viper.SetDefault("REJECT_UNKNOWN_FIELDS", false)
 
 	if err := viper.ReadInConfig(); err != nil {
 		var vipErr viper.ConfigFileNotFoundError
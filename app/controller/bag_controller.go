@@ -3,88 +3,213 @@ package controller
 import (
 	"errors"
 	"net/http"
+	"strconv"
 
-	"cuboid-challenge/app/db"
+	"cuboid-challenge/app/api"
+	"cuboid-challenge/app/inventory"
 	"cuboid-challenge/app/models"
+	"cuboid-challenge/app/units"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
-func ListBags(c *gin.Context) {
-	var bags []models.Bag
-	if r := db.CONN.Preload("Cuboids").Find(&bags); r.Error != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": r.Error.Error()})
+// ListBags returns a page of bags with their cuboids, see parseListQuery for the parameters.
+// The volume filters are in the canonical unit.
+func (h *Controller) ListBags(c *gin.Context) {
+	q, err := parseListQuery(c, api.BagList)
+	if err != nil {
+		abortWithProblem(c, err)
 
 		return
 	}
 
+	unit, err := responseUnit(c)
+	if err != nil {
+		abortWithProblem(c, err)
+
+		return
+	}
+
+	bags, total, cursor, err := h.store.Bags().List(q)
+	if err != nil {
+		abortWithProblem(c, err)
+
+		return
+	}
+
+	next, err := api.EncodeCursor(q, cursor)
+	if err != nil {
+		abortWithProblem(c, err)
+
+		return
+	}
+
+	for i := range bags {
+		bags[i].Unit = unit
+	}
+
+	setPageHeaders(c, total, next)
 	c.JSON(http.StatusOK, bags)
 }
 
-func GetBag(c *gin.Context) {
-	bagID := c.Param("bagID")
+func (h *Controller) GetBag(c *gin.Context) {
+	bagID, err := strconv.ParseUint(c.Param("bagID"), 10, 64)
+	if err != nil {
+		abortWithProblem(c, api.ErrNotFound)
 
-	var bag models.Bag
-	if r := db.CONN.Preload("Cuboids").First(&bag, bagID); r.Error != nil {
-		if errors.Is(r.Error, gorm.ErrRecordNotFound) {
-			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Not Found"})
-		} else {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": r.Error.Error()})
-		}
+		return
+	}
+
+	unit, err := responseUnit(c)
+	if err != nil {
+		abortWithProblem(c, err)
+
+		return
+	}
+
+	bag, err := h.store.Bags().Get(uint(bagID), includeDeleted(c))
+	if err != nil {
+		abortWithProblem(c, err)
 
 		return
 	}
+	bag.Unit = unit
 
+	setETag(c, bag.Version)
 	c.JSON(http.StatusOK, &bag)
 }
 
-func CreateBag(c *gin.Context) {
+// CreateBag creates an empty bag. The sizes are in the `unit` of the body, the volume in its cube,
+// see CreateCuboid.
+func (h *Controller) CreateBag(c *gin.Context) {
 	var bagInput struct {
 		Title  string
-		Volume uint
+		Volume units.Value
+		Width  units.Value
+		Height units.Value
+		Depth  units.Value
+		Unit   units.Unit
 	}
 
-	if err := c.BindJSON(&bagInput); err != nil {
+	unit, err := responseUnit(c)
+	if err != nil {
+		abortWithProblem(c, err)
+
 		return
 	}
 
+	if !bindJSON(c, &bagInput) {
+		return
+	}
+
+	conv := newSizeConverter(bagInput.Unit)
 	bag := models.Bag{
 		Title:    bagInput.Title,
-		Volume:   bagInput.Volume,
+		Volume:   conv.volume("Volume", bagInput.Volume),
+		Width:    conv.length("Width", bagInput.Width),
+		Height:   conv.length("Height", bagInput.Height),
+		Depth:    conv.length("Depth", bagInput.Depth),
 		Cuboids:  []models.Cuboid{},
 		Disabled: false,
+		Unit:     unit,
 	}
-	if r := db.CONN.Create(&bag); r.Error != nil {
-		var err models.ValidationErrors
-		if ok := errors.As(r.Error, &err); ok {
-			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		} else {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": r.Error.Error()})
-		}
+	if err := conv.err(); err != nil {
+		abortWithProblem(c, err)
+
+		return
+	}
+	if err := h.inventory.CreateBag(&bag); err != nil {
+		abortWithProblem(c, err)
 
 		return
 	}
 
+	setETag(c, bag.Version)
 	c.JSON(http.StatusCreated, &bag)
 }
 
-func DeleteBag(c *gin.Context) {
-	bagID := c.Param("bagID")
+// UpdateBag changes the title, volume and disabled flag of a bag. Fields left out of the body keep their value.
+// The volume is in the cube of the `unit` of the body, see inventory.Service.UpdateBag for the rules.
+func (h *Controller) UpdateBag(c *gin.Context) {
+	bagID, err := strconv.ParseUint(c.Param("bagID"), 10, 64)
+	if err != nil {
+		abortWithProblem(c, api.ErrNotFound)
 
-	var bag models.Bag
-	if r := db.CONN.First(&bag, bagID); r.Error != nil {
-		if errors.Is(r.Error, gorm.ErrRecordNotFound) {
-			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Not Found"})
-		} else {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": r.Error.Error()})
+		return
+	}
+
+	unit, err := responseUnit(c)
+	if err != nil {
+		abortWithProblem(c, err)
+
+		return
+	}
+
+	var bagInput struct {
+		Title    *string
+		Volume   *units.Value
+		Unit     units.Unit
+		Disabled *bool
+	}
+
+	if !bindJSON(c, &bagInput) {
+		return
+	}
+
+	change := inventory.BagChange{Title: bagInput.Title, Disabled: bagInput.Disabled}
+	if bagInput.Volume != nil {
+		conv := newSizeConverter(bagInput.Unit)
+		volume := conv.volume("Volume", *bagInput.Volume)
+		if err := conv.err(); err != nil {
+			abortWithProblem(c, err)
+
+			return
 		}
+		change.Volume = &volume
+	}
+
+	bag, err := h.inventory.UpdateBag(uint(bagID), ifMatchOf(c), change)
+	if err != nil {
+		// the bag is the resource of the request, not a bag it refers to
+		if errors.Is(err, inventory.ErrBagNotFound) {
+			err = api.ErrNotFound
+		}
+		abortWithProblem(c, err)
+
+		return
+	}
+	bag.Unit = unit
+
+	setETag(c, bag.Version)
+	c.JSON(http.StatusOK, &bag)
+}
+
+// DeleteBag deletes the bag and applies the selected policy to its cuboids: refuse to delete a bag
+// that still has cuboids (the default), delete them with the bag, or move them to `targetBagId`.
+func (h *Controller) DeleteBag(c *gin.Context) {
+	bagID, err := strconv.ParseUint(c.Param("bagID"), 10, 64)
+	if err != nil {
+		abortWithProblem(c, api.ErrNotFound)
 
 		return
 	}
 
-	if r := db.CONN.Delete(&bag); r.Error != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": r.Error.Error()})
+	policy := inventory.DeletePolicy(c.DefaultQuery("cuboids", string(inventory.DeleteRefuse)))
+	var targetID uint64
+	if policy == inventory.DeleteMove {
+		if targetID, err = strconv.ParseUint(c.Query("targetBagId"), 10, 64); err != nil {
+			abortWithProblem(c, inventory.ErrInvalidMoveTarget)
+
+			return
+		}
+	}
+
+	err = h.inventory.DeleteBag(uint(bagID), ifMatchOf(c), policy, uint(targetID))
+	if err != nil {
+		if errors.Is(err, inventory.ErrBagNotFound) {
+			err = api.ErrNotFound
+		}
+		abortWithProblem(c, err)
 
 		return
 	}
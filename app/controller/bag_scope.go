@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"strconv"
+
+	"cuboid-challenge/app/api"
+	"cuboid-challenge/app/inventory"
+	"cuboid-challenge/app/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+const bagScopeKey = "bagScope"
+
+// BagScope serves the cuboid handlers under /bags/:bagID/cuboids. It checks the bag exists and, on routes
+// with a cuboidID, that the cuboid is in the bag, so a cuboid is never reached through another bag.
+func (h *Controller) BagScope(c *gin.Context) {
+	bagID, err := strconv.ParseUint(c.Param("bagID"), 10, 64)
+	if err != nil {
+		abortWithProblem(c, inventory.ErrBagNotFound)
+
+		return
+	}
+
+	exists, err := h.store.Bags().Exists(uint(bagID), includeDeleted(c))
+	if err != nil {
+		abortWithProblem(c, err)
+
+		return
+	}
+
+	if !exists {
+		abortWithProblem(c, inventory.ErrBagNotFound)
+
+		return
+	}
+
+	if v := c.Param("cuboidID"); v != "" {
+		cuboidID, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			abortWithProblem(c, api.ErrNotFound)
+
+			return
+		}
+
+		cuboid, err := h.store.Cuboids().Get(uint(cuboidID), includeDeleted(c))
+		if err == nil && cuboid.BagID != uint(bagID) {
+			err = store.ErrNotFound
+		}
+		if err != nil {
+			abortWithProblem(c, err)
+
+			return
+		}
+	}
+
+	c.Set(bagScopeKey, uint(bagID))
+	c.Next()
+}
+
+// scopedBagID returns the bag of a nested route, and false on the flat /cuboids routes.
+func scopedBagID(c *gin.Context) (uint, bool) {
+	v, ok := c.Get(bagScopeKey)
+	if !ok {
+		return 0, false
+	}
+
+	return v.(uint), true
+}
@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+
+	"cuboid-challenge/app/api"
+	"cuboid-challenge/app/config"
+	"cuboid-challenge/app/i18n"
+	"cuboid-challenge/app/models"
+	"cuboid-challenge/app/units"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bodyError is a request body that could not be decoded. Field is the JSON name of the bad field, and
+// key the message catalog key that describes what is wrong with it.
+type bodyError struct {
+	field string
+	key   string
+	param string
+	cause string
+}
+
+func (e *bodyError) Error() string {
+	return fmt.Sprintf("%s. %s", api.ErrInvalidBody.Error(), e.cause)
+}
+
+func (e *bodyError) Unwrap() error {
+	return api.ErrInvalidBody
+}
+
+// localize describes the error in the given locale.
+func (e *bodyError) localize(loc i18n.Locale) string {
+	if e.key == "" {
+		return api.Capitalize(e.cause)
+	}
+
+	msg, ok := i18n.FieldMessage(loc, e.field, e.param, e.key)
+	if !ok {
+		return api.Capitalize(e.cause)
+	}
+
+	return msg
+}
+
+// readBody reads the request body, up to config.ENV.MaxBodyBytes.
+func readBody(c *gin.Context) ([]byte, error) {
+	if c.Request.Body == nil {
+		return nil, nil
+	}
+
+	limit := config.ENV.MaxBodyBytes
+	body, err := ioutil.ReadAll(io.LimitReader(c.Request.Body, limit+1))
+	if err != nil {
+		return nil, &bodyError{cause: err.Error()}
+	}
+
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("%w, the limit is %d bytes", api.ErrBodyTooLarge, limit)
+	}
+
+	return body, nil
+}
+
+// bindJSON decodes the request body into obj. It rejects the fields obj does not have when
+// config.ENV.RejectUnknownFields is set. On failure the request is aborted with a problem
+// naming the bad field, and false is returned.
+func bindJSON(c *gin.Context, obj interface{}) bool {
+	body, err := readBody(c)
+	if err == nil {
+		err = decodeJSON(body, obj)
+	}
+
+	if err != nil {
+		abortWithProblem(c, err)
+
+		return false
+	}
+
+	return true
+}
+
+func decodeJSON(body []byte, obj interface{}) error {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return &bodyError{cause: "the request body is empty"}
+	}
+
+	d := json.NewDecoder(bytes.NewReader(body))
+	if config.ENV.RejectUnknownFields {
+		d.DisallowUnknownFields()
+	}
+
+	if err := d.Decode(obj); err != nil {
+		return jsonBodyError(err)
+	}
+
+	if d.More() {
+		return &bodyError{cause: "unexpected data after the JSON value"}
+	}
+
+	return nil
+}
+
+// jsonBodyError turns an encoding/json error into a bodyError.
+func jsonBodyError(err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.As(err, &syntaxErr):
+		return &bodyError{cause: fmt.Sprintf("malformed JSON at offset %d, %s", syntaxErr.Offset, syntaxErr.Error())}
+	case errors.Is(err, units.ErrUnknownUnit):
+		return unitBodyError(err)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return &bodyError{cause: "malformed JSON, the body ends too early"}
+	case errors.As(err, &typeErr):
+		field := fieldPath(typeErr.Field)
+
+		return &bodyError{
+			field: field,
+			key:   "type." + jsonKind(typeErr.Type),
+			cause: fmt.Sprintf("%s can not be a %s", field, typeErr.Value),
+		}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json has no type for this error
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+
+		return &bodyError{field: field, key: "unknown", cause: fmt.Sprintf("unknown field %s", field)}
+	default:
+		return &bodyError{cause: err.Error()}
+	}
+}
+
+// fieldPath converts the Go field path of a decoding error to the JSON names, the body itself has no path.
+func fieldPath(path string) string {
+	if path == "" {
+		return "body"
+	}
+
+	parts := strings.Split(path, ".")
+	for i := range parts {
+		parts[i] = models.JSONName(parts[i])
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// jsonKind names the kind of JSON value a Go type takes.
+func jsonKind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "uint"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Ptr:
+		return jsonKind(t.Elem())
+	default:
+		return "object"
+	}
+}
@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"cuboid-challenge/app/api"
+	"cuboid-challenge/app/inventory"
+	"cuboid-challenge/app/models"
+	"cuboid-challenge/app/store"
+	"cuboid-challenge/app/units"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxBulkItems = 1000
+
+// bulkResult reports how one item of a bulk request went. Status is the one the item alone would get,
+// Code and Error are the problem code and detail when it failed.
+type bulkResult struct {
+	Index  int            `json:"index"`
+	Status int            `json:"status"`
+	Code   string         `json:"code,omitempty"`
+	Error  string         `json:"error,omitempty"`
+	Cuboid *models.Cuboid `json:"cuboid,omitempty"`
+}
+
+func (r *bulkResult) fail(err error) {
+	t, detail := api.ProblemFor(err)
+	r.Status, r.Code, r.Error = t.Status, t.Code, detail
+	r.Cuboid = nil
+}
+
+// bulkFailed tells whether any item failed.
+func bulkFailed(results []bulkResult) bool {
+	for _, r := range results {
+		if r.Error != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CreateCuboids creates a batch of cuboids, for one or several bags, in a single transaction.
+// Every cuboid is checked against what its bag has left once the cuboids before it are in,
+// and nothing is created unless all of them fit. Each cuboid has the sizes in its own `unit`.
+func (h *Controller) CreateCuboids(c *gin.Context) {
+	var cuboidsInput []struct {
+		Width  units.Value
+		Height units.Value
+		Depth  units.Value
+		Unit   units.Unit
+		BagID  uint `json:"bagId"`
+		Rotate bool
+	}
+
+	unit, err := responseUnit(c)
+	if err != nil {
+		abortWithProblem(c, err)
+
+		return
+	}
+
+	if !bindJSON(c, &cuboidsInput) {
+		return
+	}
+
+	if len(cuboidsInput) == 0 || len(cuboidsInput) > maxBulkItems {
+		abortWithProblem(c, fmt.Errorf("%w, expected between 1 and %d cuboids", api.ErrInvalidBulkSize, maxBulkItems))
+
+		return
+	}
+
+	items := make([]inventory.NewCuboid, len(cuboidsInput))
+	for i, in := range cuboidsInput {
+		conv := newSizeConverter(in.Unit)
+		cuboid := models.Cuboid{
+			Width:  conv.length("Width", in.Width),
+			Height: conv.length("Height", in.Height),
+			Depth:  conv.length("Depth", in.Depth),
+			BagID:  in.BagID,
+			Unit:   unit,
+		}
+		items[i] = inventory.NewCuboid{Cuboid: &cuboid, Rotate: in.Rotate, Err: conv.err()}
+	}
+
+	err = h.inventory.AddCuboids(items)
+
+	results := make([]bulkResult, len(items))
+	for i := range items {
+		results[i] = bulkResult{Index: i, Status: http.StatusCreated, Cuboid: items[i].Cuboid}
+		if items[i].Err != nil {
+			results[i].fail(items[i].Err)
+		}
+	}
+
+	if err != nil {
+		if errors.Is(err, inventory.ErrCuboidsRejected) {
+			err = fmt.Errorf("%w, no cuboid was created", api.ErrBulkFailed)
+		}
+		abortWithProblem(c, err, gin.H{"results": results})
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"results": results})
+}
+
+// DeleteCuboids deletes a batch of cuboids given by id, all of them or none when one is missing.
+func (h *Controller) DeleteCuboids(c *gin.Context) {
+	var ids []uint
+
+	if !bindJSON(c, &ids) {
+		return
+	}
+
+	if len(ids) == 0 || len(ids) > maxBulkItems {
+		abortWithProblem(c, fmt.Errorf("%w, expected between 1 and %d cuboid ids", api.ErrInvalidBulkSize, maxBulkItems))
+
+		return
+	}
+
+	results := make([]bulkResult, len(ids))
+	err := h.store.Transaction(func(tx store.Store) error {
+		found, err := tx.Cuboids().Existing(ids)
+		if err != nil {
+			return err
+		}
+
+		present := make(map[uint]bool, len(found))
+		for _, id := range found {
+			present[id] = true
+		}
+
+		seen := map[uint]bool{}
+		for i, id := range ids {
+			results[i] = bulkResult{Index: i, Status: http.StatusOK}
+			switch {
+			case !present[id]:
+				results[i].fail(api.ErrNotFound)
+			case seen[id]:
+				results[i].fail(fmt.Errorf("%w, the cuboid id is duplicated", api.ErrInvalidBody))
+			}
+			seen[id] = true
+		}
+
+		if bulkFailed(results) {
+			return api.ErrBulkFailed
+		}
+
+		return tx.Cuboids().DeleteMany(ids)
+	})
+	if err != nil {
+		if errors.Is(err, api.ErrBulkFailed) {
+			err = fmt.Errorf("%w, no cuboid was deleted", err)
+		}
+		abortWithProblem(c, err, gin.H{"results": results})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
@@ -0,0 +1,18 @@
+package controller
+
+import (
+	"cuboid-challenge/app/inventory"
+	"cuboid-challenge/app/store"
+)
+
+// Controller holds the handlers of the API. They read the records from its store and change them
+// through the inventory service, which applies the rules.
+type Controller struct {
+	store     store.Store
+	inventory *inventory.Service
+}
+
+// New returns the controller on the store.
+func New(s store.Store) *Controller {
+	return &Controller{store: s, inventory: inventory.New(s)}
+}
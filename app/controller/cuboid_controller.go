@@ -1,218 +1,292 @@
 package controller
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 
-	"cuboid-challenge/app/db"
+	"cuboid-challenge/app/api"
+	"cuboid-challenge/app/inventory"
 	"cuboid-challenge/app/models"
-
-	"gorm.io/gorm"
+	"cuboid-challenge/app/store"
+	"cuboid-challenge/app/units"
 
 	"github.com/gin-gonic/gin"
 )
 
-var (
-	ErrBagNotFound          = errors.New("bag not Found")
-	ErrCuboidNotFound       = errors.New("cuboid not Found")
-	ErrInternalServer       = errors.New("internal server error")
-	ErrInsufficientCapacity = errors.New("insufficient capacity in bag")
-	ErrBagIsDisabled        = errors.New("bag is disabled")
-)
-
-func GetCuboid(c *gin.Context) {
-	cuboidID := c.Param("cuboidID")
+var ErrInternalServer = errors.New("internal server error")
 
-	var cuboid models.Cuboid
-	if r := db.CONN.First(&cuboid, cuboidID); r.Error != nil {
-		if errors.Is(r.Error, gorm.ErrRecordNotFound) {
-			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Not Found"})
-		} else {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": r.Error.Error()})
-		}
+func (h *Controller) GetCuboid(c *gin.Context) {
+	cuboidID, err := strconv.ParseUint(c.Param("cuboidID"), 10, 64)
+	if err != nil {
+		abortWithProblem(c, api.ErrNotFound)
 
 		return
 	}
 
-	c.JSON(http.StatusOK, &cuboid)
-}
+	unit, err := responseUnit(c)
+	if err != nil {
+		abortWithProblem(c, err)
 
-func ListCuboids(c *gin.Context) {
-	var cuboids []models.Cuboid
-	if r := db.CONN.Find(&cuboids); r.Error != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": r.Error.Error()})
+		return
+	}
+
+	cuboid, err := h.store.Cuboids().Get(uint(cuboidID), includeDeleted(c))
+	if err != nil {
+		abortWithProblem(c, err)
 
 		return
 	}
+	cuboid.Unit = unit
 
-	c.JSON(http.StatusOK, cuboids)
+	setETag(c, cuboid.Version)
+	c.JSON(http.StatusOK, &cuboid)
 }
 
-func ValidateCuboidBeforeCreate(cuboid models.Cuboid) error {
-	var bag models.Bag
-	if r := db.CONN.Preload("Cuboids").First(&bag, cuboid.BagID); r.Error != nil {
-		if errors.Is(r.Error, gorm.ErrRecordNotFound) {
-			return ErrBagNotFound
-		}
+// ListCuboids returns a page of cuboids, see parseListQuery for the parameters.
+// The volume filters are in the canonical unit.
+func (h *Controller) ListCuboids(c *gin.Context) {
+	q, err := parseListQuery(c, api.CuboidList)
+	if err != nil {
+		abortWithProblem(c, err)
 
-		return ErrInternalServer
+		return
 	}
 
-	if bag.Disabled {
-		return ErrBagIsDisabled
+	unit, err := responseUnit(c)
+	if err != nil {
+		abortWithProblem(c, err)
+
+		return
 	}
 
-	if bag.AvailableVolume() < cuboid.PayloadVolume() {
-		return ErrInsufficientCapacity
+	if bagID, ok := scopedBagID(c); ok {
+		q.BagID = bagID
 	}
 
-	return nil
-}
+	cuboids, total, cursor, err := h.store.Cuboids().List(q)
+	if err != nil {
+		abortWithProblem(c, err)
 
-func ValidateCuboidBeforeUpdate(cuboid, cuboidForUpdate models.Cuboid) error {
-	var bag models.Bag
-	if r := db.CONN.Preload("Cuboids").First(&bag, cuboid.BagID); r.Error != nil {
-		if errors.Is(r.Error, gorm.ErrRecordNotFound) {
-			return ErrBagNotFound
-		}
+		return
+	}
+
+	next, err := api.EncodeCursor(q, cursor)
+	if err != nil {
+		abortWithProblem(c, err)
 
-		return ErrInternalServer
+		return
 	}
-	// todo I commented it because don't find any requirement or test case for it.
-	/*if bag.Disabled {
-		return ErrBagIsDisabled
-	}*/
 
-	if bag.AvailableVolume()+cuboid.PayloadVolume() < cuboidForUpdate.PayloadVolume() {
-		return ErrInsufficientCapacity
+	for i := range cuboids {
+		cuboids[i].Unit = unit
 	}
 
-	return nil
+	setPageHeaders(c, total, next)
+	c.JSON(http.StatusOK, cuboids)
 }
 
-func CreateCuboid(c *gin.Context) {
+// CreateCuboid creates a cuboid in a bag. The sizes are in the `unit` of the body, the canonical unit when
+// it names none, and must be a whole number of the canonical unit.
+func (h *Controller) CreateCuboid(c *gin.Context) {
 	var cuboidInput struct {
-		Width  uint
-		Height uint
-		Depth  uint
+		Width  units.Value
+		Height units.Value
+		Depth  units.Value
+		Unit   units.Unit
 		BagID  uint `json:"bagId"`
+		Rotate bool
+	}
+
+	unit, err := responseUnit(c)
+	if err != nil {
+		abortWithProblem(c, err)
+
+		return
 	}
 
-	if err := c.BindJSON(&cuboidInput); err != nil {
+	if !bindJSON(c, &cuboidInput) {
 		return
 	}
 
+	conv := newSizeConverter(cuboidInput.Unit)
 	cuboid := models.Cuboid{
-		Width:  cuboidInput.Width,
-		Height: cuboidInput.Height,
-		Depth:  cuboidInput.Depth,
+		Width:  conv.length("Width", cuboidInput.Width),
+		Height: conv.length("Height", cuboidInput.Height),
+		Depth:  conv.length("Depth", cuboidInput.Depth),
 		BagID:  cuboidInput.BagID,
 	}
-
-	if err := ValidateCuboidBeforeCreate(cuboid); err != nil {
-		switch {
-		case errors.Is(err, ErrBagNotFound):
-			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Bag Not Found"})
-		case errors.Is(err, ErrInternalServer):
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		case errors.Is(err, ErrInsufficientCapacity):
-			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Insufficient capacity in bag"})
-		case errors.Is(err, ErrBagIsDisabled):
-			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Bag is disabled"})
-		}
+	if err := conv.err(); err != nil {
+		abortWithProblem(c, err)
 
 		return
 	}
+	// the bag in the path wins over the one in the body
+	if bagID, ok := scopedBagID(c); ok {
+		cuboid.BagID = bagID
+	}
 
-	if r := db.CONN.Create(&cuboid); r.Error != nil {
-		var err models.ValidationErrors
-		if ok := errors.As(r.Error, &err); ok {
-			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		} else {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": r.Error.Error()})
-		}
+	if err := h.inventory.AddCuboid(&cuboid, cuboidInput.Rotate); err != nil {
+		abortWithProblem(c, err)
 
 		return
 	}
+	cuboid.Unit = unit
 
+	setETag(c, cuboid.Version)
 	c.JSON(http.StatusCreated, &cuboid)
 }
 
-func UpdateCuboid(c *gin.Context) {
-	cuboid := getCuboidByValidation(c)
+type cuboidUpdate struct {
+	Width  units.Value
+	Height units.Value
+	Depth  units.Value
+	Unit   units.Unit
+	BagID  uint `json:"bagId"`
+	Rotate bool
+}
 
-	//todo We may have dirty read problem at hear we have update with other request that our read will be dirty
-	// so we can check before update by last update date or use distributed lock like redis or apache ignite
+// UpdateCuboid resizes the cuboid and, when bagId names another bag, moves it there.
+// The checks and the write run in one transaction, so a failed move leaves the cuboid where it was.
+// The sizes are in the `unit` of the body, see CreateCuboid.
+func (h *Controller) UpdateCuboid(c *gin.Context) {
+	cuboid := h.getCuboidByValidation(c)
 
-	if c == nil {
+	if c.IsAborted() {
 		return
 	}
 
-	var cuboidInput struct {
-		Width  uint
-		Height uint
-		Depth  uint
-		BagID  uint `json:"bagId"`
+	var cuboidInput cuboidUpdate
+
+	if !bindJSON(c, &cuboidInput) {
+		return
+	}
+
+	h.saveCuboidUpdate(c, cuboid, func(models.Cuboid) (cuboidUpdate, error) {
+		return cuboidInput, nil
+	})
+}
+
+// PatchCuboid applies a JSON merge patch (RFC 7396) to the cuboid, fields left out of the patch keep their value.
+// A `unit` member is the unit of the sizes in the patch, the stored ones are not converted.
+func (h *Controller) PatchCuboid(c *gin.Context) {
+	cuboid := h.getCuboidByValidation(c)
+
+	if c.IsAborted() {
+		return
+	}
+
+	body, err := readBody(c)
+	if err != nil {
+		abortWithProblem(c, err)
+
+		return
+	}
+
+	// the numbers keep their digits for the unit conversion
+	var patch interface{}
+	d := json.NewDecoder(bytes.NewReader(body))
+	d.UseNumber()
+	if err := d.Decode(&patch); err != nil {
+		abortWithProblem(c, api.ErrInvalidPatch)
+
+		return
+	}
+
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		abortWithProblem(c, fmt.Errorf("%w, it must be a JSON object", api.ErrInvalidPatch))
+
+		return
 	}
 
-	if err := c.BindJSON(&cuboidInput); err != nil {
-		// todo return meaningful message when input is incorrect
+	if err := canonicalPatch(patchObj, "width", "height", "depth"); err != nil {
+		abortWithProblem(c, err)
+
 		return
 	}
 
-	cuboidForUpdate := models.Cuboid{Depth: cuboidInput.Depth, Width: cuboidInput.Width, Height: cuboidInput.Height}
-	if err := ValidateCuboidBeforeUpdate(cuboid, cuboidForUpdate); err != nil {
-		switch {
-		case errors.Is(err, ErrBagNotFound):
-			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Bag Not Found"})
-		case errors.Is(err, ErrInternalServer):
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		case errors.Is(err, ErrInsufficientCapacity):
-			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Insufficient capacity in bag"})
+	h.saveCuboidUpdate(c, cuboid, func(current models.Cuboid) (cuboidUpdate, error) {
+		doc := map[string]interface{}{
+			"width":  current.Width,
+			"height": current.Height,
+			"depth":  current.Depth,
+			"bagId":  current.BagID,
 		}
 
+		var merged cuboidUpdate
+		if err := remarshal(mergePatch(doc, patch), &merged); err != nil {
+			return cuboidUpdate{}, api.ErrInvalidPatch
+		}
+
+		return merged, nil
+	})
+}
+
+// saveCuboidUpdate builds the change from the cuboid as stored once its bag is locked, see inventory.Service.UpdateCuboid.
+// The sizes are converted there too, a merge patch only has the unit of the sizes it brings.
+func (h *Controller) saveCuboidUpdate(c *gin.Context, cuboid models.Cuboid, build func(current models.Cuboid) (cuboidUpdate, error)) {
+	unit, err := responseUnit(c)
+	if err != nil {
+		abortWithProblem(c, err)
+
 		return
 	}
-	if r := db.CONN.Model(&cuboid).Select("depth", "height", "width").
-		Updates(cuboidForUpdate); r.Error != nil {
-		var err models.ValidationErrors
-		if ok := errors.As(r.Error, &err); ok {
-			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		} else {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": r.Error.Error()})
+
+	cuboid, err = h.inventory.UpdateCuboid(cuboid.ID, ifMatchOf(c), func(current models.Cuboid) (inventory.CuboidChange, error) {
+		cuboidInput, err := build(current)
+		if err != nil {
+			return inventory.CuboidChange{}, err
+		}
+
+		conv := newSizeConverter(cuboidInput.Unit)
+		change := inventory.CuboidChange{
+			Width:  conv.length("Width", cuboidInput.Width),
+			Height: conv.length("Height", cuboidInput.Height),
+			Depth:  conv.length("Depth", cuboidInput.Depth),
+			BagID:  cuboidInput.BagID,
+			Rotate: cuboidInput.Rotate,
+		}
+		// the cuboid stays in the bag of the path, whatever the body says
+		if bagID, ok := scopedBagID(c); ok {
+			change.BagID = bagID
 		}
 
+		return change, conv.err()
+	})
+	if err != nil {
+		abortWithProblem(c, err)
+
 		return
 	}
+	cuboid.Unit = unit
 
+	setETag(c, cuboid.Version)
 	c.JSON(http.StatusOK, &cuboid)
 }
 
-func getCuboidByValidation(c *gin.Context) models.Cuboid {
-	cuboidID := c.Param("cuboidID")
-	cuboid, err := getCuboidByID(cuboidID)
+func (h *Controller) getCuboidByValidation(c *gin.Context) models.Cuboid {
+	cuboid, err := h.getCuboidByID(c.Param("cuboidID"))
 
 	if err != nil {
-		switch {
-		case errors.Is(err, ErrCuboidNotFound):
-			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Not Found"})
-		case errors.Is(err, ErrInternalServer):
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		abortWithProblem(c, err)
 	}
 
 	return cuboid
 }
 
-func DeleteCuboid(c *gin.Context) {
-	cuboid := getCuboidByValidation(c)
+func (h *Controller) DeleteCuboid(c *gin.Context) {
+	cuboid := h.getCuboidByValidation(c)
 
-	if c == nil {
+	if c.IsAborted() {
 		return
 	}
 
-	if r := db.CONN.Model(&cuboid).Delete("id = ?", &cuboid.ID); r.Error != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": r.Error.Error()})
+	if err := h.inventory.DeleteCuboid(cuboid.ID, ifMatchOf(c)); err != nil {
+		abortWithProblem(c, err)
 
 		return
 	}
@@ -220,14 +294,20 @@ func DeleteCuboid(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "Cuboid is Removed"})
 }
 
-func getCuboidByID(cuboidID string) (models.Cuboid, error) {
-	var cuboid models.Cuboid
-	if r := db.CONN.First(&cuboid, cuboidID); r.Error != nil {
-		if errors.Is(r.Error, gorm.ErrRecordNotFound) {
-			return models.Cuboid{}, ErrCuboidNotFound
+func (h *Controller) getCuboidByID(cuboidID string) (models.Cuboid, error) {
+	id, err := strconv.ParseUint(cuboidID, 10, 64)
+	if err != nil {
+		return models.Cuboid{}, inventory.ErrCuboidNotFound
+	}
+
+	cuboid, err := h.store.Cuboids().Get(uint(id), false)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return models.Cuboid{}, inventory.ErrCuboidNotFound
 		}
 
 		return models.Cuboid{}, ErrInternalServer
 	}
+
 	return cuboid, nil
 }
@@ -0,0 +1,38 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	"cuboid-challenge/app/inventory"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setETag exposes the record version, so clients can send it back in If-Match.
+func setETag(c *gin.Context, version uint) {
+	c.Header("ETag", fmt.Sprintf(`"%d"`, version))
+}
+
+// ifMatch reports whether the If-Match header allows a change to a record at version.
+// Requests without the header are always allowed. If-Match compares strongly, a weak tag never matches.
+func ifMatch(c *gin.Context, version uint) bool {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return true
+	}
+
+	etag := fmt.Sprintf(`"%d"`, version)
+	for _, tag := range strings.Split(header, ",") {
+		if strings.TrimSpace(tag) == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ifMatchOf is the precondition the If-Match header sets on a change.
+func ifMatchOf(c *gin.Context) inventory.Precondition {
+	return func(version uint) bool { return ifMatch(c, version) }
+}
@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"cuboid-challenge/app/api"
+	"cuboid-challenge/app/inventory"
+	"cuboid-challenge/app/models"
+	"cuboid-challenge/app/units"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fitAlternative struct {
+	ID              uint        `json:"id"`
+	Title           string      `json:"title"`
+	AvailableVolume json.Number `json:"availableVolume"`
+}
+
+// GetBagFit answers whether a cuboid would fit in the bag without creating it. When it does not,
+// the enabled bags that could take it are listed, the tightest fit first.
+// The sizes are in the `unit` query parameter, which is also the unit of the response.
+func (h *Controller) GetBagFit(c *gin.Context) {
+	var fitInput struct {
+		Width  string `form:"width"`
+		Height string `form:"height"`
+		Depth  string `form:"depth"`
+		Rotate bool   `form:"rotate"`
+	}
+
+	if err := c.ShouldBindQuery(&fitInput); err != nil {
+		abortWithProblem(c, fmt.Errorf("%w. %s", api.ErrInvalidQuery, err.Error()))
+
+		return
+	}
+
+	unit, err := responseUnit(c)
+	if err != nil {
+		abortWithProblem(c, err)
+
+		return
+	}
+
+	sizes := make([]units.Value, 3)
+	for i, s := range []string{fitInput.Width, fitInput.Height, fitInput.Depth} {
+		if s == "" {
+			continue
+		}
+
+		if sizes[i], err = units.ParseValue(s); err != nil {
+			abortWithProblem(c, fmt.Errorf("%w. %s", api.ErrInvalidQuery, err.Error()))
+
+			return
+		}
+	}
+
+	conv := newSizeConverter(unit)
+	cuboid := models.Cuboid{
+		Width:  conv.length("Width", sizes[0]),
+		Height: conv.length("Height", sizes[1]),
+		Depth:  conv.length("Depth", sizes[2]),
+	}
+	if err := conv.err(); err != nil {
+		abortWithProblem(c, err)
+
+		return
+	}
+
+	if ok, err := models.Validate(cuboid); !ok {
+		abortWithProblem(c, err)
+
+		return
+	}
+
+	bagID, err := strconv.ParseUint(c.Param("bagID"), 10, 64)
+	if err != nil {
+		abortWithProblem(c, api.ErrNotFound)
+
+		return
+	}
+
+	fit, err := h.inventory.CheckFit(uint(bagID), cuboid, fitInput.Rotate)
+	if err != nil {
+		// the bag is the resource of the request
+		if errors.Is(err, inventory.ErrBagNotFound) {
+			err = api.ErrNotFound
+		}
+		abortWithProblem(c, err)
+
+		return
+	}
+
+	alternatives := make([]fitAlternative, len(fit.Alternatives))
+	for i, a := range fit.Alternatives {
+		alternatives[i] = fitAlternative{a.Bag.ID, a.Bag.Title, unit.RenderVolume(a.Available)}
+	}
+
+	reason, reasonCode := fitReason(fit.Err)
+	c.JSON(http.StatusOK, gin.H{
+		"bagId":        fit.Bag.ID,
+		"fits":         fit.Err == nil,
+		"reason":       reason,
+		"reasonCode":   reasonCode,
+		"alternatives": alternatives,
+	})
+}
+
+// fitReason returns the problem title and code of the reason the cuboid does not fit, as in the gRPC
+// answer, or nils when it fits.
+func fitReason(err error) (reason, code interface{}) {
+	if err == nil {
+		return nil, nil
+	}
+
+	t, detail := api.ProblemFor(err)
+
+	return detail, t.Code
+}
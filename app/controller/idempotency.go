@@ -0,0 +1,159 @@
+package controller
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+
+	"cuboid-challenge/app/api"
+	"cuboid-challenge/app/config"
+	"cuboid-challenge/app/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// responseRecorder keeps a copy of the response body while it is written.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+
+	return w.ResponseWriter.Write(b)
+}
+
+// Idempotent replays the stored response when a request comes again with the same Idempotency-Key,
+// within config.ENV.IdempotencyWindow. A key sent again with another request gets a 422.
+// Server errors are not stored, a retry runs the request again.
+func (h *Controller) Idempotent(c *gin.Context) {
+	key := c.GetHeader("Idempotency-Key")
+	if key == "" {
+		c.Next()
+
+		return
+	}
+
+	if len(key) > 255 {
+		abortWithProblem(c, fmt.Errorf("%w, it is longer than 255 characters", api.ErrInvalidIdempotencyKey))
+
+		return
+	}
+
+	body, err := readBody(c)
+	if err != nil {
+		abortWithProblem(c, err)
+
+		return
+	}
+	c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	hash := sha256.New()
+	hash.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "\n"))
+	hash.Write(body)
+	record := models.IdempotencyKey{Key: key, RequestHash: hex.EncodeToString(hash.Sum(nil))}
+
+	stored, err := h.claimIdempotencyKey(&record)
+	if err != nil {
+		abortWithProblem(c, err)
+
+		return
+	}
+
+	if stored != nil {
+		if stored.ETag != "" {
+			c.Header("ETag", stored.ETag)
+		}
+		contentType := stored.ContentType
+		if contentType == "" {
+			// stored before the content type was
+			contentType = "application/json; charset=utf-8"
+		}
+		c.Header("Idempotent-Replayed", "true")
+		c.Data(stored.Status, contentType, stored.Body)
+		c.Abort()
+
+		return
+	}
+
+	w := &responseRecorder{ResponseWriter: c.Writer}
+	c.Writer = w
+
+	finished := false
+	defer func() {
+		// a panic in the handler leaves no response to store, the key is freed so a retry runs again
+		if !finished {
+			h.releaseIdempotencyKey(&record)
+		}
+	}()
+
+	c.Next()
+
+	record.Status, record.ETag = c.Writer.Status(), c.Writer.Header().Get("ETag")
+	record.ContentType, record.Body = c.Writer.Header().Get("Content-Type"), w.body.Bytes()
+	h.finishIdempotencyKey(&record)
+	finished = true
+}
+
+// claimIdempotencyKey stores the key for a new request, or returns the stored response of the request
+// that already used it. A key older than the window is taken over by the new request.
+func (h *Controller) claimIdempotencyKey(record *models.IdempotencyKey) (*models.IdempotencyKey, error) {
+	keys := h.store.IdempotencyKeys()
+	record.CreatedAt = h.store.Now()
+
+	claimed, err := keys.Claim(record)
+	if err != nil || claimed {
+		return nil, err
+	}
+
+	stored, err := keys.Get(record.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	if stored.CreatedAt.Before(record.CreatedAt.Add(-config.ENV.IdempotencyWindow)) {
+		// only one of the requests racing for an expired key gets to replace it
+		replaced, err := keys.Replace(stored.CreatedAt, record)
+		if err != nil || replaced {
+			return nil, err
+		}
+
+		return nil, api.ErrKeyInProgress
+	}
+
+	switch {
+	case stored.RequestHash != record.RequestHash:
+		return nil, api.ErrKeyReused
+	case stored.Status == 0:
+		return nil, api.ErrKeyInProgress
+	}
+
+	return &stored, nil
+}
+
+// finishIdempotencyKey stores the response of the request, or frees the key after a server error.
+// A response that can not be stored frees the key as well, rather than leaving it in progress.
+func (h *Controller) finishIdempotencyKey(record *models.IdempotencyKey) {
+	keys := h.store.IdempotencyKeys()
+	if record.Status < http.StatusInternalServerError {
+		err := keys.Finish(record)
+		if err == nil {
+			return
+		}
+		log.Printf("Failed to store the response for Idempotency-Key %q. %v", record.Key, err)
+	}
+
+	h.releaseIdempotencyKey(record)
+}
+
+// releaseIdempotencyKey frees the key, so a retry runs the request again.
+func (h *Controller) releaseIdempotencyKey(record *models.IdempotencyKey) {
+	if err := h.store.IdempotencyKeys().Release(record.Key, record.CreatedAt); err != nil {
+		log.Printf("Failed to release Idempotency-Key %q. %v", record.Key, err)
+	}
+}
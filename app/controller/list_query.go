@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"strconv"
+
+	"cuboid-challenge/app/api"
+	"cuboid-challenge/app/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseListQuery reads a page request: `limit`, `cursor`, `sort` (a key, `-` prefixed for descending
+// order) and the filters. Deleted records are included when the request asks for them.
+func parseListQuery(c *gin.Context, spec api.ListSpec) (store.ListQuery, error) {
+	params := api.ListParams{
+		Cursor:         c.Query("cursor"),
+		Sort:           c.Query("sort"),
+		IncludeDeleted: isTrue(c.Query("includeDeleted")),
+		Filters:        map[string]string{},
+	}
+
+	if v, ok := c.GetQuery("limit"); ok {
+		// a limit that is not a number is out of range as well
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			limit = 0
+		}
+		params.Limit = &limit
+	}
+
+	for name, v := range c.Request.URL.Query() {
+		params.Filters[name] = v[0]
+	}
+
+	return spec.Parse(params)
+}
+
+// setPageHeaders exposes the total count of the filtered records and, when there are more, the next page cursor.
+func setPageHeaders(c *gin.Context, total int64, next string) {
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+	if next != "" {
+		c.Header("X-Next-Cursor", next)
+	}
+}
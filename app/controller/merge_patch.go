@@ -0,0 +1,37 @@
+package controller
+
+import "encoding/json"
+
+// mergePatch applies patch to target following RFC 7396: null removes a member, objects are merged
+// recursively and any other value replaces the target.
+func mergePatch(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = map[string]interface{}{}
+	}
+
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+		} else {
+			targetObj[key] = mergePatch(targetObj[key], value)
+		}
+	}
+
+	return targetObj
+}
+
+// remarshal decodes a generic JSON document into out.
+func remarshal(doc interface{}, out interface{}) error {
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, out)
+}
@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"cuboid-challenge/app/api"
+	"cuboid-challenge/app/models"
+	"cuboid-challenge/app/packing"
+	"cuboid-challenge/app/units"
+
+	"github.com/gin-gonic/gin"
+)
+
+// packingItem is a cuboid of the request, the unplaced ones are answered as they were sent.
+type packingItem struct {
+	Width  units.Value `json:"width"`
+	Height units.Value `json:"height"`
+	Depth  units.Value `json:"depth"`
+}
+
+type packingAssignment struct {
+	Index  int            `json:"index"`
+	BagID  uint           `json:"bagId"`
+	Cuboid *models.Cuboid `json:"cuboid"`
+}
+
+type packingUnplaced struct {
+	Index int `json:"index"`
+	packingItem
+}
+
+// CreatePacking assigns a batch of cuboids across the candidate bags, or every enabled bag when
+// none is given. The cuboids are only created when the request asks to commit the assignment.
+// The sizes of the cuboids are in the `unit` of the body, a request takes up to maxBulkItems cuboids.
+func (h *Controller) CreatePacking(c *gin.Context) {
+	var packingInput struct {
+		Cuboids  []packingItem
+		Unit     units.Unit
+		BagIDs   []uint `json:"bagIds"`
+		Strategy packing.Strategy
+		Rotate   bool
+		Commit   bool
+	}
+
+	unit, err := responseUnit(c)
+	if err != nil {
+		abortWithProblem(c, err)
+
+		return
+	}
+
+	if !bindJSON(c, &packingInput) {
+		return
+	}
+
+	if len(packingInput.Cuboids) == 0 || len(packingInput.Cuboids) > maxBulkItems {
+		abortWithProblem(c, fmt.Errorf("%w, expected between 1 and %d cuboids", api.ErrInvalidBulkSize, maxBulkItems))
+
+		return
+	}
+
+	if packingInput.Strategy == "" {
+		packingInput.Strategy = packing.FirstFitDecreasing
+	}
+
+	if !packingInput.Strategy.IsValid() {
+		abortWithProblem(c, api.ErrUnknownStrategy)
+
+		return
+	}
+
+	items := make([]packing.Dims, len(packingInput.Cuboids))
+	for i, item := range packingInput.Cuboids {
+		conv := newSizeConverter(packingInput.Unit)
+		cuboid := models.Cuboid{
+			Width:  conv.length("Width", item.Width),
+			Height: conv.length("Height", item.Height),
+			Depth:  conv.length("Depth", item.Depth),
+		}
+		if err := conv.err(); err != nil {
+			abortWithProblem(c, err, gin.H{"index": i})
+
+			return
+		}
+
+		if ok, err := models.Validate(cuboid); !ok {
+			abortWithProblem(c, err, gin.H{"index": i})
+
+			return
+		}
+		items[i] = cuboid.Dims()
+	}
+
+	assigned, unplacedIdx, err := h.inventory.Pack(packingInput.BagIDs, items, packingInput.Strategy, packingInput.Rotate, packingInput.Commit)
+	if err != nil {
+		abortWithProblem(c, err)
+
+		return
+	}
+
+	result := make([]packingAssignment, len(assigned))
+	for i, a := range assigned {
+		result[i] = packingAssignment{Index: a.Index, BagID: a.Cuboid.BagID, Cuboid: a.Cuboid}
+	}
+
+	for i := range result {
+		result[i].Cuboid.Unit = unit
+	}
+
+	unplaced := make([]packingUnplaced, len(unplacedIdx))
+	for i, idx := range unplacedIdx {
+		unplaced[i] = packingUnplaced{Index: idx, packingItem: packingInput.Cuboids[idx]}
+	}
+
+	status := http.StatusOK
+	if packingInput.Commit && len(result) > 0 {
+		status = http.StatusCreated
+	}
+
+	c.JSON(status, gin.H{
+		"strategy":    packingInput.Strategy,
+		"committed":   status == http.StatusCreated,
+		"assignments": result,
+		"unplaced":    unplaced,
+	})
+}
@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"cuboid-challenge/app/api"
+	"cuboid-challenge/app/i18n"
+	"cuboid-challenge/app/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fieldProblem is a models.FieldError in a validation problem.
+type fieldProblem struct {
+	Field   string `json:"field"`
+	Type    string `json:"type"`
+	Param   string `json:"param"`
+	Message string `json:"message"`
+}
+
+// abortWithProblem ends the request with the problem document for err. The `error` member repeats
+// the detail for the clients of the former error responses. ext adds members to the document.
+// Validation and request body messages are in the locale the Accept-Language header prefers,
+// a bad field of the body is named in the `field` member.
+func abortWithProblem(c *gin.Context, err error, ext ...gin.H) {
+	t, detail := api.ProblemFor(err)
+	loc := i18n.Negotiate(c.GetHeader("Accept-Language"))
+
+	doc := gin.H{
+		"type":     t.URI(),
+		"title":    t.Title,
+		"status":   t.Status,
+		"detail":   detail,
+		"instance": c.Request.URL.Path,
+		"code":     t.Code,
+		"error":    detail,
+	}
+
+	var bodyErr *bodyError
+	if errors.As(err, &bodyErr) {
+		doc["detail"], doc["error"] = bodyErr.localize(loc), bodyErr.localize(loc)
+		if bodyErr.field != "" {
+			doc["field"] = bodyErr.field
+		}
+	}
+
+	var valErr models.ValidationErrors
+	if errors.As(err, &valErr) {
+		fields := make([]fieldProblem, len(valErr))
+		for i := range valErr {
+			fe := valErr[i]
+			fields[i] = fieldProblem{models.JSONName(fe.Field), fe.Type, fe.Param, fe.Localize(loc)}
+		}
+		doc["errors"] = fields
+		doc["detail"], doc["error"] = valErr.Localize(loc), valErr.Localize(loc)
+		c.Header("Content-Language", string(loc))
+	}
+
+	for _, h := range ext {
+		for k, v := range h {
+			doc[k] = v
+		}
+	}
+
+	c.Header("Content-Type", "application/problem+json")
+	c.AbortWithStatusJSON(t.Status, doc)
+}
+
+// ListProblems documents every problem type the API answers with.
+func ListProblems(c *gin.Context) {
+	docs := make([]gin.H, len(api.ProblemTypes))
+	for i, t := range api.ProblemTypes {
+		docs[i] = gin.H{"type": t.URI(), "code": t.Code, "status": t.Status, "title": t.Title}
+	}
+
+	c.JSON(http.StatusOK, docs)
+}
+
+// GetProblem documents the problem type with the given code, the target of the `type` member.
+func GetProblem(c *gin.Context) {
+	for _, t := range api.ProblemTypes {
+		if t.Code == c.Param("code") {
+			c.JSON(http.StatusOK, gin.H{"type": t.URI(), "code": t.Code, "status": t.Status, "title": t.Title})
+
+			return
+		}
+	}
+
+	abortWithProblem(c, api.ErrNotFound)
+}
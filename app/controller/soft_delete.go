@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"cuboid-challenge/app/api"
+	"cuboid-challenge/app/inventory"
+
+	"github.com/gin-gonic/gin"
+)
+
+// includeDeleted reports whether the request asks for soft deleted records with `includeDeleted=true`.
+func includeDeleted(c *gin.Context) bool {
+	return isTrue(c.Query("includeDeleted"))
+}
+
+func isTrue(s string) bool {
+	v, err := strconv.ParseBool(s)
+
+	return err == nil && v
+}
+
+// RestoreBag brings back a soft deleted bag with the cuboids that were deleted along with it.
+func (h *Controller) RestoreBag(c *gin.Context) {
+	bagID, err := strconv.ParseUint(c.Param("bagID"), 10, 64)
+	if err != nil {
+		abortWithProblem(c, api.ErrNotFound)
+
+		return
+	}
+
+	unit, err := responseUnit(c)
+	if err != nil {
+		abortWithProblem(c, err)
+
+		return
+	}
+
+	bag, err := h.inventory.RestoreBag(uint(bagID), ifMatchOf(c))
+	if err != nil {
+		// the bag is the resource of the request
+		if errors.Is(err, inventory.ErrBagNotFound) {
+			err = api.ErrNotFound
+		}
+		abortWithProblem(c, err)
+
+		return
+	}
+	bag.Unit = unit
+
+	setETag(c, bag.Version)
+	c.JSON(http.StatusOK, &bag)
+}
+
+// RestoreCuboid brings back a soft deleted cuboid. Its bag must not be deleted and must still have room for it.
+func (h *Controller) RestoreCuboid(c *gin.Context) {
+	cuboidID, err := strconv.ParseUint(c.Param("cuboidID"), 10, 64)
+	if err != nil {
+		abortWithProblem(c, api.ErrNotFound)
+
+		return
+	}
+
+	unit, err := responseUnit(c)
+	if err != nil {
+		abortWithProblem(c, err)
+
+		return
+	}
+
+	cuboid, err := h.inventory.RestoreCuboid(uint(cuboidID), ifMatchOf(c))
+	if err != nil {
+		abortWithProblem(c, err)
+
+		return
+	}
+	cuboid.Unit = unit
+
+	setETag(c, cuboid.Version)
+	c.JSON(http.StatusOK, &cuboid)
+}
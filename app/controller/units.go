@@ -0,0 +1,156 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"cuboid-challenge/app/api"
+	"cuboid-challenge/app/models"
+	"cuboid-challenge/app/units"
+
+	"github.com/gin-gonic/gin"
+)
+
+// responseUnit returns the unit the client wants the sizes of the response in, from the `unit` query
+// parameter or else the X-Unit header. It is the canonical unit when neither is given.
+func responseUnit(c *gin.Context) (units.Unit, error) {
+	name, ok := c.GetQuery("unit")
+	if !ok {
+		name = c.GetHeader("X-Unit")
+	}
+
+	return units.Parse(name)
+}
+
+// sizeConverter converts the sizes of a request from the unit it declares to the canonical unit,
+// collecting a validation error for every size that does not convert. A size that is not a number
+// makes the body invalid instead.
+type sizeConverter struct {
+	unit    units.Unit
+	errs    models.ValidationErrors
+	bodyErr error
+}
+
+func newSizeConverter(unit units.Unit) *sizeConverter {
+	if unit == "" {
+		unit = units.Canonical
+	}
+
+	return &sizeConverter{unit: unit}
+}
+
+// length converts the size of the named field, a Go field name like the ones of models.Validate.
+func (s *sizeConverter) length(field string, v units.Value) uint {
+	n, err := s.unit.Length(v)
+	s.check(field, err, models.MaxDimension)
+
+	return n
+}
+
+// volume converts the volume of the named field, see length.
+func (s *sizeConverter) volume(field string, v units.Value) uint {
+	n, err := s.unit.Volume(v)
+	s.check(field, err, models.MaxVolume)
+
+	return n
+}
+
+func (s *sizeConverter) check(field string, err error, max uint64) {
+	switch {
+	case err == nil:
+	case errors.Is(err, units.ErrNotANumber):
+		if s.bodyErr == nil {
+			name := models.JSONName(field)
+			s.bodyErr = &bodyError{field: name, key: "type.decimal", cause: fmt.Sprintf("%s is %s", name, err)}
+		}
+	case errors.Is(err, units.ErrInexact):
+		s.errs = append(s.errs, models.FieldError{Field: field, Type: "inexact", Param: string(units.Canonical)})
+	default:
+		s.errs = append(s.errs, models.FieldError{Field: field, Type: "max", Param: strconv.FormatUint(max, 10)})
+	}
+}
+
+// err returns the conversion errors, or nil when every size converted.
+func (s *sizeConverter) err() error {
+	if s.bodyErr != nil {
+		return s.bodyErr
+	}
+
+	if len(s.errs) == 0 {
+		return nil
+	}
+
+	return s.errs
+}
+
+// canonicalPatch converts the size members of a merge patch to the canonical unit when the patch
+// declares a `unit`, and drops the member, so they merge with the stored sizes. Members that are
+// not numbers are left for the decoding to reject.
+func canonicalPatch(patch map[string]interface{}, fields ...string) error {
+	raw, ok := patch["unit"]
+	if !ok {
+		return nil
+	}
+	delete(patch, "unit")
+
+	name, ok := raw.(string)
+	if !ok && raw != nil {
+		return unitBodyError(fmt.Errorf("%w, got a %s", units.ErrUnknownUnit, jsonTypeName(raw)))
+	}
+
+	unit, err := units.Parse(name)
+	if err != nil {
+		return unitBodyError(err)
+	}
+
+	conv := newSizeConverter(unit)
+	for _, field := range fields {
+		n, ok := patch[field].(json.Number)
+		if !ok {
+			continue
+		}
+
+		v, err := units.ParseValue(string(n))
+		if err != nil {
+			continue
+		}
+
+		size := conv.length(api.Capitalize(field), v)
+		patch[field] = json.Number(strconv.FormatUint(uint64(size), 10))
+	}
+
+	return conv.err()
+}
+
+// unitBodyError is the error of a request body naming an unknown unit.
+func unitBodyError(err error) error {
+	return &bodyError{field: "unit", key: "unit.unknown", param: unitNames(), cause: err.Error()}
+}
+
+func unitNames() string {
+	names := ""
+	for i, u := range units.Units() {
+		if i > 0 {
+			names += ", "
+		}
+		names += string(u)
+	}
+
+	return names
+}
+
+// jsonTypeName names the kind of a generic JSON value the way encoding/json does in its errors.
+func jsonTypeName(v interface{}) string {
+	switch v.(type) {
+	case bool:
+		return "bool"
+	case json.Number, float64:
+		return "number"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
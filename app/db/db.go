@@ -2,13 +2,18 @@ package db
 
 import (
 	"fmt"
+	"time"
 
 	"cuboid-challenge/app/config"
 
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
+const sqliteBusyTimeoutMs = 5000
+
 // CONN global gorm db connection.
 var CONN *gorm.DB
 
@@ -19,24 +24,64 @@ func Connect() *gorm.DB {
 	}
 
 	var err error
-	if CONN, err = gorm.Open(driver(), &gorm.Config{}); err != nil {
+	if CONN, err = gorm.Open(driver(), &gorm.Config{NowFunc: now}); err != nil {
 		panic(fmt.Errorf("failed to open the database connection. %w", err))
 	}
 
+	if err := configurePool(CONN); err != nil {
+		panic(fmt.Errorf("failed to configure the database connection pool. %w", err))
+	}
+
 	registerDBValidationsHooks(CONN)
 
 	return CONN
 }
 
+// now is the time gorm stores, in milliseconds. MySQL keeps milliseconds and Postgres microseconds, so
+// a time read back equals the one that was written and can be used in a condition.
+func now() time.Time {
+	return time.Now().Truncate(time.Millisecond)
+}
+
 func driver() gorm.Dialector {
 	d := config.ENV.DBDriver
-	if d == "sqlite" {
-		return sqlite.Open(config.ENV.DBName)
+	switch d {
+	case "sqlite":
+		// concurrent writers wait for the lock instead of failing with SQLITE_BUSY, transactions
+		// take the write lock when they begin so a capacity check is never based on a stale read,
+		// and foreign keys are enforced, SQLite leaves them off by default
+		return sqlite.Open(fmt.Sprintf(
+			"%s?_busy_timeout=%d&_txlock=immediate&_foreign_keys=1", config.ENV.DBName, sqliteBusyTimeoutMs,
+		))
+	case "postgres":
+		return postgres.Open(config.ENV.DBDSN)
+	case "mysql":
+		// the DSN needs parseTime=true to read the timestamps
+		return mysql.Open(config.ENV.DBDSN)
 	}
 
 	panic(driverErr{d}.Error())
 }
 
+func configurePool(conn *gorm.DB) error {
+	sqlDB, err := conn.DB()
+	if err != nil {
+		return err
+	}
+
+	if config.ENV.DBMaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(config.ENV.DBMaxOpenConns)
+	}
+	if config.ENV.DBMaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(config.ENV.DBMaxIdleConns)
+	}
+	if config.ENV.DBConnMaxLifetime > 0 {
+		sqlDB.SetConnMaxLifetime(config.ENV.DBConnMaxLifetime)
+	}
+
+	return nil
+}
+
 type driverErr struct{ name string }
 
 func (d driverErr) Error() string {
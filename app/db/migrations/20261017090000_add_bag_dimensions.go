@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func init() {
+	migrations = append(migrations, &gormigrate.Migration{
+		ID: "20261017090000",
+		Migrate: func(tx *gorm.DB) error {
+			fmt.Println("Running migration add_bag_dimensions")
+			type Bag struct {
+				Width  uint
+				Height uint
+				Depth  uint
+			}
+
+			return tx.AutoMigrate(&Bag{})
+		},
+		Rollback: func(tx *gorm.DB) error {
+			fmt.Println("Rollback migration add_bag_dimensions")
+			type Bag struct{}
+
+			for _, column := range []string{"width", "height", "depth"} {
+				if err := tx.Migrator().DropColumn(&Bag{}, column); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	})
+}
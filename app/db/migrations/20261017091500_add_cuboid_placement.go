@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func init() {
+	migrations = append(migrations, &gormigrate.Migration{
+		ID: "20261017091500",
+		Migrate: func(tx *gorm.DB) error {
+			fmt.Println("Running migration add_cuboid_placement")
+			type Cuboid struct {
+				X           uint
+				Y           uint
+				Z           uint
+				Orientation string
+			}
+
+			return tx.AutoMigrate(&Cuboid{})
+		},
+		Rollback: func(tx *gorm.DB) error {
+			fmt.Println("Rollback migration add_cuboid_placement")
+			type Cuboid struct{}
+
+			for _, column := range []string{"x", "y", "z", "orientation"} {
+				if err := tx.Migrator().DropColumn(&Cuboid{}, column); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	})
+}
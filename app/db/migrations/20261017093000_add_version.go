@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func init() {
+	migrations = append(migrations, &gormigrate.Migration{
+		ID: "20261017093000",
+		Migrate: func(tx *gorm.DB) error {
+			fmt.Println("Running migration add_version")
+			type Bag struct {
+				Version uint `gorm:"not null;default:1"`
+			}
+			type Cuboid struct {
+				Version uint `gorm:"not null;default:1"`
+			}
+
+			return tx.AutoMigrate(&Bag{}, &Cuboid{})
+		},
+		Rollback: func(tx *gorm.DB) error {
+			fmt.Println("Rollback migration add_version")
+			type Bag struct{}
+			type Cuboid struct{}
+
+			if err := tx.Migrator().DropColumn(&Bag{}, "version"); err != nil {
+				return err
+			}
+
+			return tx.Migrator().DropColumn(&Cuboid{}, "version")
+		},
+	})
+}
@@ -0,0 +1,91 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// SQLite cannot add a constraint to an existing table, the table is built again with it.
+func rebuildCuboids(tx *gorm.DB, cuboid interface{}) error {
+	columns := "id, version, width, height, depth, x, y, z, orientation, bag_id"
+	queries := []func() error{
+		func() error { return tx.Migrator().RenameTable("cuboids", "cuboids__old") },
+		func() error { return tx.Migrator().CreateTable(cuboid) },
+		func() error {
+			return tx.Exec(fmt.Sprintf("INSERT INTO cuboids (%s) SELECT %s FROM cuboids__old", columns, columns)).Error
+		},
+		func() error { return tx.Migrator().DropTable("cuboids__old") },
+	}
+
+	for _, query := range queries {
+		if err := query(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func init() {
+	type Bag struct {
+		ID uint
+	}
+
+	type Cuboid struct {
+		ID          uint `gorm:"primarykey"`
+		Version     uint `gorm:"not null;default:1"`
+		Width       uint
+		Height      uint
+		Depth       uint
+		X           uint
+		Y           uint
+		Z           uint
+		Orientation string
+		BagID       uint
+		Bag         Bag `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	}
+
+	migrations = append(migrations, &gormigrate.Migration{
+		ID: "20261017094500",
+		Migrate: func(tx *gorm.DB) error {
+			fmt.Println("Running migration add_cuboid_bag_foreign_key")
+
+			// cuboids of bags deleted before the constraint existed point at nothing
+			r := tx.Exec("DELETE FROM cuboids WHERE bag_id NOT IN (SELECT id FROM bags)")
+			if r.Error != nil {
+				return r.Error
+			}
+			fmt.Printf("Deleted %d orphaned cuboids\n", r.RowsAffected)
+
+			if tx.Dialector.Name() == "sqlite" {
+				return rebuildCuboids(tx, &Cuboid{})
+			}
+
+			return tx.Migrator().CreateConstraint(&Cuboid{}, "Bag")
+		},
+		Rollback: func(tx *gorm.DB) error {
+			fmt.Println("Rollback migration add_cuboid_bag_foreign_key")
+
+			if tx.Dialector.Name() == "sqlite" {
+				type Cuboid struct {
+					ID          uint `gorm:"primarykey"`
+					Version     uint `gorm:"not null;default:1"`
+					Width       uint
+					Height      uint
+					Depth       uint
+					X           uint
+					Y           uint
+					Z           uint
+					Orientation string
+					BagID       uint
+				}
+
+				return rebuildCuboids(tx, &Cuboid{})
+			}
+
+			return tx.Migrator().DropConstraint(&Cuboid{}, "Bag")
+		},
+	})
+}
@@ -0,0 +1,46 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func init() {
+	migrations = append(migrations, &gormigrate.Migration{
+		ID: "20261017100000",
+		Migrate: func(tx *gorm.DB) error {
+			fmt.Println("Running migration add_soft_delete")
+			type Bag struct {
+				DeletedAt gorm.DeletedAt `gorm:"index"`
+			}
+			type Cuboid struct {
+				DeletedAt gorm.DeletedAt `gorm:"index"`
+			}
+
+			return tx.AutoMigrate(&Bag{}, &Cuboid{})
+		},
+		Rollback: func(tx *gorm.DB) error {
+			fmt.Println("Rollback migration add_soft_delete")
+			type Bag struct {
+				DeletedAt gorm.DeletedAt `gorm:"index"`
+			}
+			type Cuboid struct {
+				DeletedAt gorm.DeletedAt `gorm:"index"`
+			}
+
+			for _, model := range []interface{}{&Bag{}, &Cuboid{}} {
+				if err := tx.Migrator().DropIndex(model, "DeletedAt"); err != nil {
+					return err
+				}
+
+				if err := tx.Migrator().DropColumn(model, "deleted_at"); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	})
+}
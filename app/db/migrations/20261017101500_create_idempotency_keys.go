@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func init() {
+	migrations = append(migrations, &gormigrate.Migration{
+		ID: "20261017101500",
+		Migrate: func(tx *gorm.DB) error {
+			fmt.Println("Running migration create_idempotency_keys")
+			type IdempotencyKey struct {
+				Key         string `gorm:"primarykey;size:255"`
+				RequestHash string `gorm:"size:64;not null"`
+				Status      int    `gorm:"not null;default:0"`
+				ETag        string `gorm:"size:64"`
+				Body        []byte
+				CreatedAt   time.Time `gorm:"index"`
+			}
+
+			return tx.AutoMigrate(&IdempotencyKey{})
+		},
+		Rollback: func(tx *gorm.DB) error {
+			fmt.Println("Rollback migration create_idempotency_keys")
+			type IdempotencyKey struct{}
+
+			return tx.Migrator().DropTable(&IdempotencyKey{})
+		},
+	})
+}
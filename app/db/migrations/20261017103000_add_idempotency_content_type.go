@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func init() {
+	migrations = append(migrations, &gormigrate.Migration{
+		ID: "20261017103000",
+		Migrate: func(tx *gorm.DB) error {
+			fmt.Println("Running migration add_idempotency_content_type")
+			type IdempotencyKey struct {
+				ContentType string `gorm:"size:255"`
+			}
+
+			return tx.AutoMigrate(&IdempotencyKey{})
+		},
+		Rollback: func(tx *gorm.DB) error {
+			fmt.Println("Rollback migration add_idempotency_content_type")
+			type IdempotencyKey struct{}
+
+			return tx.Migrator().DropColumn(&IdempotencyKey{}, "content_type")
+		},
+	})
+}
@@ -0,0 +1,281 @@
+package graph
+
+import (
+	"errors"
+	"strings"
+
+	"cuboid-challenge/app/api"
+	"cuboid-challenge/app/inventory"
+	"cuboid-challenge/app/models"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+// bagResolver resolves a bag. The cuboids of the bags of a list come from their loader, the ones of a
+// single bag are read along with it.
+type bagResolver struct {
+	bag    models.Bag
+	loader *cuboidLoader
+}
+
+func (r *bagResolver) ID() graphql.ID     { return toID(r.bag.ID) }
+func (r *bagResolver) Version() int32     { return int32(r.bag.Version) }
+func (r *bagResolver) Title() string      { return r.bag.Title }
+func (r *bagResolver) Volume() float64    { return float64(r.bag.Volume) }
+func (r *bagResolver) Width() int32       { return int32(r.bag.Width) }
+func (r *bagResolver) Height() int32      { return int32(r.bag.Height) }
+func (r *bagResolver) Depth() int32       { return int32(r.bag.Depth) }
+func (r *bagResolver) Disabled() bool     { return r.bag.Disabled }
+func (r *bagResolver) DeletedAt() *string { return deletedAt(r.bag.DeletedAt) }
+
+// withCuboids returns the bag with its cuboids.
+func (r *bagResolver) withCuboids() (models.Bag, error) {
+	if r.loader == nil {
+		return r.bag, nil
+	}
+
+	bag := r.bag
+	cuboids, err := r.loader.load(bag.ID)
+	bag.Cuboids = cuboids
+
+	return bag, err
+}
+
+func (r *bagResolver) PayloadVolume() (float64, error) {
+	bag, err := r.withCuboids()
+	if err != nil {
+		return 0, problem(err)
+	}
+
+	payload, err := bag.PayloadVolume()
+	if err != nil {
+		return 0, problem(err)
+	}
+
+	return float64(payload), nil
+}
+
+func (r *bagResolver) AvailableVolume() (float64, error) {
+	bag, err := r.withCuboids()
+	if err != nil {
+		return 0, problem(err)
+	}
+
+	available, err := bag.AvailableVolume()
+	if err != nil {
+		return 0, problem(err)
+	}
+
+	return float64(available), nil
+}
+
+func (r *bagResolver) Cuboids() ([]*cuboidResolver, error) {
+	bag, err := r.withCuboids()
+	if err != nil {
+		return nil, problem(err)
+	}
+
+	cuboids := make([]*cuboidResolver, len(bag.Cuboids))
+	for i := range bag.Cuboids {
+		cuboids[i] = &cuboidResolver{bag.Cuboids[i]}
+	}
+
+	return cuboids, nil
+}
+
+type bagConnectionResolver struct {
+	nodes []*bagResolver
+	total int64
+	next  string
+}
+
+func (r *bagConnectionResolver) Nodes() []*bagResolver { return r.nodes }
+func (r *bagConnectionResolver) TotalCount() int32     { return int32(r.total) }
+
+func (r *bagConnectionResolver) NextCursor() *string {
+	if r.next == "" {
+		return nil
+	}
+
+	return &r.next
+}
+
+type bagFilter struct {
+	Disabled           *bool
+	MinVolume          *float64
+	MaxVolume          *float64
+	MinAvailableVolume *float64
+	MaxAvailableVolume *float64
+}
+
+// Bags lists the bags without their cuboids, the loader reads the cuboids of the page at once when
+// the query selects them.
+func (r *resolver) Bags(args struct {
+	listArgs
+	Filter *bagFilter
+}) (*bagConnectionResolver, error) {
+	params := args.params()
+	if f := args.Filter; f != nil {
+		setBool(params, "disabled", f.Disabled)
+		setFloat(params, "minVolume", f.MinVolume)
+		setFloat(params, "maxVolume", f.MaxVolume)
+		setFloat(params, "minAvailableVolume", f.MinAvailableVolume)
+		setFloat(params, "maxAvailableVolume", f.MaxAvailableVolume)
+	}
+
+	q, err := api.BagList.Parse(params)
+	if err != nil {
+		return nil, problem(err)
+	}
+	q.WithoutCuboids = true
+
+	bags, total, cursor, err := r.store.Bags().List(q)
+	if err != nil {
+		return nil, problem(err)
+	}
+
+	next, err := api.EncodeCursor(q, cursor)
+	if err != nil {
+		return nil, problem(err)
+	}
+
+	loader := newCuboidLoader(r.store, q.IncludeDeleted, bags)
+	res := &bagConnectionResolver{nodes: make([]*bagResolver, len(bags)), total: total, next: next}
+	for i := range bags {
+		res.nodes[i] = &bagResolver{bags[i], loader}
+	}
+
+	return res, nil
+}
+
+func (r *resolver) Bag(args struct {
+	ID             graphql.ID
+	IncludeDeleted *bool
+}) (*bagResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, nullIfNotFound(err)
+	}
+
+	bag, err := r.store.Bags().Get(id, isSet(args.IncludeDeleted))
+	if err != nil {
+		return nil, nullIfNotFound(err)
+	}
+
+	return &bagResolver{bag: bag}, nil
+}
+
+// bagError reports an error of a mutation whose resource is the bag, a missing one is not found
+// rather than a bag the mutation refers to, like in the REST API.
+func bagError(err error) error {
+	if errors.Is(err, inventory.ErrBagNotFound) {
+		err = api.ErrNotFound
+	}
+
+	return problem(err)
+}
+
+// CreateBag creates an empty bag, its volume is the one of the dimensions when left out.
+func (r *resolver) CreateBag(args struct {
+	Input struct {
+		Title  string
+		Volume *float64
+		Width  *int32
+		Height *int32
+		Depth  *int32
+	}
+}) (*bagResolver, error) {
+	in := args.Input
+	bag := models.Bag{Title: in.Title, Cuboids: []models.Cuboid{}}
+
+	var err error
+	if in.Volume != nil {
+		if bag.Volume, err = volume(*in.Volume); err != nil {
+			return nil, problem(err)
+		}
+	}
+
+	dims := []struct {
+		name  string
+		value *int32
+		dst   *uint
+	}{{"width", in.Width, &bag.Width}, {"height", in.Height, &bag.Height}, {"depth", in.Depth, &bag.Depth}}
+	for _, d := range dims {
+		if d.value == nil {
+			continue
+		}
+
+		if *d.dst, err = size(d.name, *d.value); err != nil {
+			return nil, problem(err)
+		}
+	}
+
+	if err := r.inventory.CreateBag(&bag); err != nil {
+		return nil, problem(err)
+	}
+
+	return &bagResolver{bag: bag}, nil
+}
+
+// UpdateBag changes the fields set in the input, see inventory.Service.UpdateBag for the rules.
+func (r *resolver) UpdateBag(args struct {
+	ID    graphql.ID
+	Input struct {
+		Title    *string
+		Volume   *float64
+		Disabled *bool
+	}
+	ExpectedVersion *int32
+}) (*bagResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, problem(err)
+	}
+
+	change := inventory.BagChange{Title: args.Input.Title, Disabled: args.Input.Disabled}
+	if args.Input.Volume != nil {
+		v, err := volume(*args.Input.Volume)
+		if err != nil {
+			return nil, problem(err)
+		}
+		change.Volume = &v
+	}
+
+	bag, err := r.inventory.UpdateBag(id, expectVersion(args.ExpectedVersion), change)
+	if err != nil {
+		return nil, bagError(err)
+	}
+
+	return &bagResolver{bag: bag}, nil
+}
+
+// DeleteBag deletes the bag and applies the policy to its cuboids, see DeletePolicy.
+func (r *resolver) DeleteBag(args struct {
+	ID              graphql.ID
+	Cuboids         string
+	TargetBagID     *graphql.ID
+	ExpectedVersion *int32
+}) (bool, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return false, problem(err)
+	}
+
+	policy := inventory.DeletePolicy(strings.ToLower(args.Cuboids))
+	var targetID uint
+	if policy == inventory.DeleteMove {
+		if args.TargetBagID == nil {
+			return false, problem(inventory.ErrInvalidMoveTarget)
+		}
+
+		if targetID, err = parseID(*args.TargetBagID); err != nil {
+			return false, problem(inventory.ErrInvalidMoveTarget)
+		}
+	}
+
+	if err := r.inventory.DeleteBag(id, expectVersion(args.ExpectedVersion), policy, targetID); err != nil {
+		return false, bagError(err)
+	}
+
+	return true, nil
+}
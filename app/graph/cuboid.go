@@ -0,0 +1,228 @@
+package graph
+
+import (
+	"cuboid-challenge/app/api"
+	"cuboid-challenge/app/inventory"
+	"cuboid-challenge/app/models"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+type cuboidResolver struct {
+	cuboid models.Cuboid
+}
+
+func (r *cuboidResolver) ID() graphql.ID     { return toID(r.cuboid.ID) }
+func (r *cuboidResolver) Version() int32     { return int32(r.cuboid.Version) }
+func (r *cuboidResolver) Width() int32       { return int32(r.cuboid.Width) }
+func (r *cuboidResolver) Height() int32      { return int32(r.cuboid.Height) }
+func (r *cuboidResolver) Depth() int32       { return int32(r.cuboid.Depth) }
+func (r *cuboidResolver) BagID() graphql.ID  { return toID(r.cuboid.BagID) }
+func (r *cuboidResolver) DeletedAt() *string { return deletedAt(r.cuboid.DeletedAt) }
+
+func (r *cuboidResolver) Volume() (float64, error) {
+	volume, err := r.cuboid.PayloadVolume()
+	if err != nil {
+		return 0, problem(err)
+	}
+
+	return float64(volume), nil
+}
+
+func (r *cuboidResolver) Placement() *placementResolver {
+	p, ok := r.cuboid.Placement()
+	if !ok {
+		return nil
+	}
+
+	return &placementResolver{int32(p.X), int32(p.Y), int32(p.Z), string(p.Orientation)}
+}
+
+type placementResolver struct {
+	x, y, z     int32
+	orientation string
+}
+
+func (r *placementResolver) X() int32            { return r.x }
+func (r *placementResolver) Y() int32            { return r.y }
+func (r *placementResolver) Z() int32            { return r.z }
+func (r *placementResolver) Orientation() string { return r.orientation }
+
+type cuboidConnectionResolver struct {
+	nodes []*cuboidResolver
+	total int64
+	next  string
+}
+
+func (r *cuboidConnectionResolver) Nodes() []*cuboidResolver { return r.nodes }
+func (r *cuboidConnectionResolver) TotalCount() int32        { return int32(r.total) }
+
+func (r *cuboidConnectionResolver) NextCursor() *string {
+	if r.next == "" {
+		return nil
+	}
+
+	return &r.next
+}
+
+type cuboidFilter struct {
+	BagID     *graphql.ID
+	Disabled  *bool
+	MinVolume *float64
+	MaxVolume *float64
+}
+
+func (r *resolver) Cuboids(args struct {
+	listArgs
+	Filter *cuboidFilter
+}) (*cuboidConnectionResolver, error) {
+	params := args.params()
+	if f := args.Filter; f != nil {
+		if f.BagID != nil {
+			params.Filters["bagId"] = string(*f.BagID)
+		}
+		setBool(params, "disabled", f.Disabled)
+		setFloat(params, "minVolume", f.MinVolume)
+		setFloat(params, "maxVolume", f.MaxVolume)
+	}
+
+	q, err := api.CuboidList.Parse(params)
+	if err != nil {
+		return nil, problem(err)
+	}
+
+	cuboids, total, cursor, err := r.store.Cuboids().List(q)
+	if err != nil {
+		return nil, problem(err)
+	}
+
+	next, err := api.EncodeCursor(q, cursor)
+	if err != nil {
+		return nil, problem(err)
+	}
+
+	res := &cuboidConnectionResolver{nodes: make([]*cuboidResolver, len(cuboids)), total: total, next: next}
+	for i := range cuboids {
+		res.nodes[i] = &cuboidResolver{cuboids[i]}
+	}
+
+	return res, nil
+}
+
+func (r *resolver) Cuboid(args struct {
+	ID             graphql.ID
+	IncludeDeleted *bool
+}) (*cuboidResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, nullIfNotFound(err)
+	}
+
+	cuboid, err := r.store.Cuboids().Get(id, isSet(args.IncludeDeleted))
+	if err != nil {
+		return nil, nullIfNotFound(err)
+	}
+
+	return &cuboidResolver{cuboid}, nil
+}
+
+// cuboidSizes returns the sizes of a cuboid input.
+func cuboidSizes(width, height, depth int32) (w, h, d uint, err error) {
+	if w, err = size("width", width); err != nil {
+		return
+	}
+
+	if h, err = size("height", height); err != nil {
+		return
+	}
+
+	d, err = size("depth", depth)
+
+	return
+}
+
+// CreateCuboid creates a cuboid in a bag that admits it.
+func (r *resolver) CreateCuboid(args struct {
+	Input struct {
+		Width  int32
+		Height int32
+		Depth  int32
+		BagID  graphql.ID
+		Rotate *bool
+	}
+}) (*cuboidResolver, error) {
+	in := args.Input
+
+	var cuboid models.Cuboid
+	var err error
+	if cuboid.Width, cuboid.Height, cuboid.Depth, err = cuboidSizes(in.Width, in.Height, in.Depth); err != nil {
+		return nil, problem(err)
+	}
+
+	// a malformed bag id is a missing bag, like an unknown one
+	if cuboid.BagID, err = parseID(in.BagID); err != nil {
+		return nil, problem(inventory.ErrBagNotFound)
+	}
+
+	if err := r.inventory.AddCuboid(&cuboid, isSet(in.Rotate)); err != nil {
+		return nil, problem(err)
+	}
+
+	return &cuboidResolver{cuboid}, nil
+}
+
+// UpdateCuboid resizes the cuboid and, when bagId names another bag, moves it there.
+// A failed move leaves the cuboid where it was.
+func (r *resolver) UpdateCuboid(args struct {
+	ID    graphql.ID
+	Input struct {
+		Width  int32
+		Height int32
+		Depth  int32
+		BagID  *graphql.ID
+		Rotate *bool
+	}
+	ExpectedVersion *int32
+}) (*cuboidResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, problem(err)
+	}
+
+	in := args.Input
+	change := inventory.CuboidChange{Rotate: isSet(in.Rotate)}
+	if change.Width, change.Height, change.Depth, err = cuboidSizes(in.Width, in.Height, in.Depth); err != nil {
+		return nil, problem(err)
+	}
+
+	if in.BagID != nil {
+		if change.BagID, err = parseID(*in.BagID); err != nil {
+			return nil, problem(inventory.ErrBagNotFound)
+		}
+	}
+
+	cuboid, err := r.inventory.UpdateCuboid(id, expectVersion(args.ExpectedVersion), func(models.Cuboid) (inventory.CuboidChange, error) {
+		return change, nil
+	})
+	if err != nil {
+		return nil, problem(err)
+	}
+
+	return &cuboidResolver{cuboid}, nil
+}
+
+func (r *resolver) DeleteCuboid(args struct {
+	ID              graphql.ID
+	ExpectedVersion *int32
+}) (bool, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return false, problem(err)
+	}
+
+	if err := r.inventory.DeleteCuboid(id, expectVersion(args.ExpectedVersion)); err != nil {
+		return false, problem(err)
+	}
+
+	return true, nil
+}
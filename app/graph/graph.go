@@ -0,0 +1,136 @@
+// Package graph serves the GraphQL API, see schema.graphql. The resolvers read the records from the
+// store and change them through the inventory service, like the REST handlers, and answer the same errors.
+package graph
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"cuboid-challenge/app/api"
+	"cuboid-challenge/app/config"
+	"cuboid-challenge/app/i18n"
+	"cuboid-challenge/app/inventory"
+	"cuboid-challenge/app/models"
+	"cuboid-challenge/app/store"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graph-gophers/graphql-go"
+)
+
+//go:embed schema.graphql
+var schema string
+
+// NewSchema returns the schema of the API, its resolvers read and write the records through the store.
+func NewSchema(s store.Store) *graphql.Schema {
+	return graphql.MustParseSchema(schema, &resolver{store: s, inventory: inventory.New(s)})
+}
+
+// Handler serves the GraphQL requests: a JSON body with the `query`, the `operationName` and the `variables`.
+// The answer is a GraphQL response, a request body that can not be read has a 400 and the problem in its errors.
+func Handler(s store.Store) gin.HandlerFunc {
+	schema := NewSchema(s)
+
+	return func(c *gin.Context) {
+		var params struct {
+			Query         string                 `json:"query"`
+			OperationName string                 `json:"operationName"`
+			Variables     map[string]interface{} `json:"variables"`
+		}
+
+		if err := readParams(c, &params); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"errors": []error{problem(err)}})
+
+			return
+		}
+
+		res := schema.Exec(c.Request.Context(), params.Query, params.OperationName, params.Variables)
+		localize(res, i18n.Negotiate(c.GetHeader("Accept-Language")))
+		c.JSON(http.StatusOK, res)
+	}
+}
+
+// localize puts the validation messages of the response in the locale, the resolvers answer them in
+// the default one as they do not see the request.
+func localize(res *graphql.Response, loc i18n.Locale) {
+	for _, qe := range res.Errors {
+		if p, ok := qe.ResolverError.(*problemError); ok && p.valErr != nil {
+			p.loc = loc
+			qe.Message, qe.Extensions = p.Error(), p.Extensions()
+		}
+	}
+}
+
+// readParams decodes the request body, up to config.ENV.MaxBodyBytes.
+func readParams(c *gin.Context, params interface{}) error {
+	if c.Request.Body == nil {
+		return api.ErrInvalidBody
+	}
+
+	limit := config.ENV.MaxBodyBytes
+	body, err := ioutil.ReadAll(io.LimitReader(c.Request.Body, limit+1))
+	if err != nil {
+		return fmt.Errorf("%w. %s", api.ErrInvalidBody, err.Error())
+	}
+
+	if int64(len(body)) > limit {
+		return fmt.Errorf("%w, the limit is %d bytes", api.ErrBodyTooLarge, limit)
+	}
+
+	if err := json.Unmarshal(body, params); err != nil {
+		return fmt.Errorf("%w. %s", api.ErrInvalidBody, err.Error())
+	}
+
+	return nil
+}
+
+// problemError is an error of a resolver, reported with the problem the REST API answers it with.
+// The validation messages are in loc.
+type problemError struct {
+	code   string
+	status int
+	detail string
+	valErr models.ValidationErrors
+	loc    i18n.Locale
+}
+
+func (e *problemError) Error() string {
+	if e.valErr != nil {
+		return e.valErr.Localize(e.loc)
+	}
+
+	return e.detail
+}
+
+func (e *problemError) Extensions() map[string]interface{} {
+	ext := map[string]interface{}{"code": e.code, "status": e.status}
+
+	// the fields of a validation error, as in the `errors` member of the REST problem
+	if e.valErr != nil {
+		fields := make([]gin.H, len(e.valErr))
+		for i := range e.valErr {
+			fe := e.valErr[i]
+			fields[i] = gin.H{"field": models.JSONName(fe.Field), "type": fe.Type, "param": fe.Param, "message": fe.Localize(e.loc)}
+		}
+		ext["errors"] = fields
+	}
+
+	return ext
+}
+
+func (e *problemError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(gin.H{"message": e.detail, "extensions": e.Extensions()})
+}
+
+// problem turns err into a problemError.
+func problem(err error) error {
+	t, detail := api.ProblemFor(err)
+	p := &problemError{code: t.Code, status: t.Status, detail: detail, loc: i18n.Default}
+	errors.As(err, &p.valErr)
+
+	return p
+}
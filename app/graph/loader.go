@@ -0,0 +1,63 @@
+package graph
+
+import (
+	"sort"
+	"sync"
+
+	"cuboid-challenge/app/models"
+	"cuboid-challenge/app/store"
+)
+
+// cuboidLoader loads the cuboids of the bags of a list in a single read, the first time one of them
+// is asked for, instead of one read per bag. The resolvers of a list run concurrently, the ones that
+// come after the read find their cuboids loaded.
+type cuboidLoader struct {
+	store       store.Store
+	withDeleted bool
+
+	mu      sync.Mutex
+	pending map[uint]bool
+	loaded  map[uint][]models.Cuboid
+}
+
+// newCuboidLoader returns the loader of the cuboids of the bags.
+func newCuboidLoader(s store.Store, withDeleted bool, bags []models.Bag) *cuboidLoader {
+	l := &cuboidLoader{store: s, withDeleted: withDeleted, pending: map[uint]bool{}, loaded: map[uint][]models.Cuboid{}}
+	for i := range bags {
+		l.pending[bags[i].ID] = true
+	}
+
+	return l
+}
+
+// load returns the cuboids of the bag, reading them along with the ones of every pending bag.
+func (l *cuboidLoader) load(bagID uint) ([]models.Cuboid, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if cuboids, ok := l.loaded[bagID]; ok {
+		return cuboids, nil
+	}
+
+	l.pending[bagID] = true
+	ids := make([]uint, 0, len(l.pending))
+	for id := range l.pending {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	cuboids, err := l.store.Cuboids().InBags(ids, l.withDeleted)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range ids {
+		l.loaded[id] = []models.Cuboid{}
+	}
+	for _, cuboid := range cuboids {
+		l.loaded[cuboid.BagID] = append(l.loaded[cuboid.BagID], cuboid)
+	}
+	l.pending = map[uint]bool{}
+
+	return l.loaded[bagID], nil
+}
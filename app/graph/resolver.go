@@ -0,0 +1,124 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"cuboid-challenge/app/api"
+	"cuboid-challenge/app/inventory"
+	"cuboid-challenge/app/store"
+
+	"github.com/graph-gophers/graphql-go"
+	"gorm.io/gorm"
+)
+
+// resolver is the root of the queries and the mutations.
+type resolver struct {
+	store     store.Store
+	inventory *inventory.Service
+}
+
+// listArgs are the arguments of the list queries, they turn into the parameters of the lists.
+type listArgs struct {
+	First          *int32
+	After          *string
+	Sort           *string
+	IncludeDeleted *bool
+}
+
+func (a listArgs) params() api.ListParams {
+	params := api.ListParams{IncludeDeleted: isSet(a.IncludeDeleted), Filters: map[string]string{}}
+	if a.First != nil {
+		limit := int(*a.First)
+		params.Limit = &limit
+	}
+
+	if a.After != nil {
+		params.Cursor = *a.After
+	}
+
+	if a.Sort != nil {
+		params.Sort = *a.Sort
+	}
+
+	return params
+}
+
+func setBool(params api.ListParams, name string, v *bool) {
+	if v != nil {
+		params.Filters[name] = strconv.FormatBool(*v)
+	}
+}
+
+func setFloat(params api.ListParams, name string, v *float64) {
+	if v != nil {
+		params.Filters[name] = strconv.FormatFloat(*v, 'f', -1, 64)
+	}
+}
+
+func isSet(v *bool) bool {
+	return v != nil && *v
+}
+
+func toID(n uint) graphql.ID {
+	return graphql.ID(strconv.FormatUint(uint64(n), 10))
+}
+
+// parseID returns the record id, a malformed one is not found like in the REST paths.
+func parseID(id graphql.ID) (uint, error) {
+	n, err := strconv.ParseUint(string(id), 10, 64)
+	if err != nil {
+		return 0, api.ErrNotFound
+	}
+
+	return uint(n), nil
+}
+
+// size returns an Int argument as a size, which can not be negative.
+func size(name string, v int32) (uint, error) {
+	if v < 0 {
+		return 0, fmt.Errorf("%w, %s can not be negative", api.ErrInvalidBody, name)
+	}
+
+	return uint(v), nil
+}
+
+// volume returns a Float argument as a volume, a whole number of the canonical unit cube.
+func volume(v float64) (uint, error) {
+	if v < 0 || v != math.Trunc(v) || v >= math.MaxUint64 {
+		return 0, fmt.Errorf("%w, volume must be a whole number", api.ErrInvalidBody)
+	}
+
+	return uint(v), nil
+}
+
+// expectVersion is the precondition of a mutation with an expectedVersion, none when it is left out.
+func expectVersion(expected *int32) inventory.Precondition {
+	if expected == nil {
+		return nil
+	}
+
+	return func(version uint) bool { return int64(version) == int64(*expected) }
+}
+
+func deletedAt(d gorm.DeletedAt) *string {
+	if !d.Valid {
+		return nil
+	}
+
+	t := d.Time.UTC().Format(time.RFC3339)
+
+	return &t
+}
+
+// nullIfNotFound answers null to a query for a record that does not exist.
+func nullIfNotFound(err error) error {
+	if errors.Is(err, store.ErrNotFound) || errors.Is(err, api.ErrNotFound) {
+		return nil
+	}
+
+	return problem(err)
+}
@@ -0,0 +1,131 @@
+// Package i18n translates the messages the API shows to its users.
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Locale is a language the messages are available in, as an ISO 639-1 code.
+type Locale string
+
+const (
+	English Locale = "en"
+	Spanish Locale = "es"
+)
+
+// Default is the locale used when the client accepts none of the supported ones.
+const Default = English
+
+// catalogs holds the field validation messages by validator tag. A `.string` suffix marks the message
+// used when the field is a string, where the tag is about the length. The `type.` keys tell which kind
+// of JSON value a field of the request body takes. {field} and {param} are replaced.
+var catalogs = map[Locale]map[string]string{
+	English: {
+		"required":   "{field} is required",
+		"gt":         "{field} must be greater than {param}",
+		"gte":        "{field} must be greater than or equal to {param}",
+		"lt":         "{field} must be less than {param}",
+		"lte":        "{field} must be less than or equal to {param}",
+		"min":        "{field} must be at least {param}",
+		"min.string": "{field} must be at least {param} characters long",
+		"max":        "{field} must be at most {param}",
+		"max.string": "{field} must be at most {param} characters long",
+		"eqdims":     "{field} must be equal to width * height * depth",
+		"inexact":    "{field} must be a whole number of {param} once converted",
+		"invalid":    "{field} is not valid",
+
+		"type.uint":    "{field} must be a non-negative integer",
+		"type.number":  "{field} must be a number",
+		"type.decimal": "{field} must be a non-negative number",
+		"type.string":  "{field} must be a string",
+		"type.boolean": "{field} must be true or false",
+		"type.array":   "{field} must be an array",
+		"type.object":  "{field} must be an object",
+		"unknown":      "{field} is not a known field",
+		"unit.unknown": "{field} must be one of {param}",
+	},
+	Spanish: {
+		"required":   "{field} es obligatorio",
+		"gt":         "{field} debe ser mayor que {param}",
+		"gte":        "{field} debe ser mayor o igual que {param}",
+		"lt":         "{field} debe ser menor que {param}",
+		"lte":        "{field} debe ser menor o igual que {param}",
+		"min":        "{field} debe ser como mínimo {param}",
+		"min.string": "{field} debe tener al menos {param} caracteres",
+		"max":        "{field} debe ser como máximo {param}",
+		"max.string": "{field} debe tener como máximo {param} caracteres",
+		"eqdims":     "{field} debe ser igual a width * height * depth",
+		"inexact":    "{field} debe ser un número entero de {param} una vez convertido",
+		"invalid":    "{field} no es válido",
+
+		"type.uint":    "{field} debe ser un entero no negativo",
+		"type.number":  "{field} debe ser un número",
+		"type.decimal": "{field} debe ser un número no negativo",
+		"type.string":  "{field} debe ser un texto",
+		"type.boolean": "{field} debe ser true o false",
+		"type.array":   "{field} debe ser una lista",
+		"type.object":  "{field} debe ser un objeto",
+		"unknown":      "{field} no es un campo conocido",
+		"unit.unknown": "{field} debe ser uno de {param}",
+	},
+}
+
+// FieldMessage returns the message for the first of the keys found in the locale catalog.
+func FieldMessage(loc Locale, field, param string, keys ...string) (string, bool) {
+	catalog, ok := catalogs[loc]
+	if !ok {
+		catalog = catalogs[Default]
+	}
+
+	for _, key := range keys {
+		if msg, ok := catalog[key]; ok {
+			return strings.NewReplacer("{field}", field, "{param}", param).Replace(msg), true
+		}
+	}
+
+	return "", false
+}
+
+// Negotiate picks the supported locale the Accept-Language header prefers, or Default.
+// Only the primary language subtag is considered, `es-AR` selects Spanish.
+func Negotiate(acceptLanguage string) Locale {
+	type candidate struct {
+		locale Locale
+		q      float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if i := strings.IndexByte(tag, '-'); i >= 0 {
+			tag = tag[:i]
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				v, err := strconv.ParseFloat(param[2:], 64)
+				if err != nil {
+					v = 0
+				}
+				q = v
+			}
+		}
+
+		if _, ok := catalogs[Locale(tag)]; ok && q > 0 {
+			candidates = append(candidates, candidate{Locale(tag), q})
+		}
+	}
+
+	if len(candidates) == 0 {
+		return Default
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+
+	return candidates[0].locale
+}
@@ -0,0 +1,203 @@
+package inventory
+
+import (
+	"errors"
+
+	"cuboid-challenge/app/models"
+	"cuboid-challenge/app/store"
+
+	"gorm.io/gorm"
+)
+
+// BagChange lists the fields of a bag to change, the nil ones keep their value.
+type BagChange struct {
+	Title    *string
+	Volume   *uint
+	Disabled *bool
+}
+
+// CreateBag creates an empty bag. Its volume is the one of its dimensions when left at 0.
+func (s *Service) CreateBag(bag *models.Bag) error {
+	if bag.Volume == 0 {
+		// an overflow leaves the volume at 0, the validation rejects the dimensions anyway
+		bag.Volume, _ = bag.Dims().Volume()
+	}
+
+	return s.store.Bags().Create(bag)
+}
+
+// UpdateBag changes the title, the volume and the disabled flag of a bag. The volume can not go below
+// what the cuboids of the bag take, a disabled bag keeps its cuboids but admits no new one.
+func (s *Service) UpdateBag(id uint, match Precondition, change BagChange) (models.Bag, error) {
+	var bag models.Bag
+	err := s.store.Transaction(func(tx store.Store) error {
+		if err := tx.Bags().Lock(id); err != nil {
+			return err
+		}
+
+		var err error
+		if bag, err = loadBag(tx, id); err != nil {
+			return err
+		}
+
+		if !match.allows(bag.Version) {
+			return ErrVersionMismatch
+		}
+
+		if change.Title != nil {
+			bag.Title = *change.Title
+		}
+		if change.Volume != nil {
+			bag.Volume = *change.Volume
+		}
+		if change.Disabled != nil {
+			bag.SetDisabled(*change.Disabled)
+		}
+
+		payload, err := bag.PayloadVolume()
+		if err != nil {
+			return err
+		}
+		if bag.Volume < payload {
+			return ErrVolumeBelowPayload
+		}
+		bag.Version++
+
+		return tx.Bags().Update(&bag, "title", "volume", "disabled", "version")
+	})
+
+	return bag, err
+}
+
+// DeletePolicy tells what happens to the cuboids of a deleted bag.
+type DeletePolicy string
+
+const (
+	// DeleteRefuse refuses to delete a bag that still has cuboids.
+	DeleteRefuse DeletePolicy = "refuse"
+	// DeleteCascade deletes the cuboids with the bag, restoring the bag brings them back.
+	DeleteCascade DeletePolicy = "cascade"
+	// DeleteMove moves the cuboids to another bag, which must admit them.
+	DeleteMove DeletePolicy = "move"
+)
+
+// DeleteBag soft deletes the bag and applies the policy to its cuboids. targetID is the bag the
+// cuboids move to with DeleteMove.
+func (s *Service) DeleteBag(id uint, match Precondition, policy DeletePolicy, targetID uint) error {
+	if policy == DeleteMove && targetID == id {
+		return ErrInvalidMoveTarget
+	}
+
+	return s.store.Transaction(func(tx store.Store) error {
+		if err := tx.Bags().Lock(id, targetID); err != nil {
+			return err
+		}
+
+		bag, err := loadBag(tx, id)
+		if err != nil {
+			return err
+		}
+
+		if !match.allows(bag.Version) {
+			return ErrVersionMismatch
+		}
+
+		deletedAt := tx.Now()
+		switch policy {
+		case DeleteRefuse:
+			if len(bag.Cuboids) > 0 {
+				return ErrBagNotEmpty
+			}
+		case DeleteCascade:
+			// the cuboids share the deletion time of the bag, so restoring the bag brings them back
+			if err := tx.Cuboids().DeleteInBag(bag.ID, deletedAt); err != nil {
+				return err
+			}
+		case DeleteMove:
+			if err := moveCuboids(tx, bag, targetID); err != nil {
+				return err
+			}
+		default:
+			return ErrUnknownDeletePolicy
+		}
+
+		// the store leaves the cuboids out, the loaded ones are stale after a move
+		bag.DeletedAt = gorm.DeletedAt{Time: deletedAt, Valid: true}
+
+		return tx.Bags().Update(&bag, "deleted_at")
+	})
+}
+
+// moveCuboids moves every cuboid of bag to the target bag, which must admit them one after the other.
+func moveCuboids(tx store.Store, bag models.Bag, targetID uint) error {
+	target, err := loadBag(tx, targetID)
+	if err != nil {
+		if errors.Is(err, ErrBagNotFound) {
+			return ErrMoveTargetNotFound
+		}
+
+		return err
+	}
+
+	for i := range bag.Cuboids {
+		moved := bag.Cuboids[i]
+		moved.BagID = target.ID
+		moved.Version++
+		// the old position means nothing in the target bag
+		moved.X, moved.Y, moved.Z, moved.Orientation = 0, 0, 0, ""
+		if err := Admits(target, &moved, true); err != nil {
+			return err
+		}
+
+		if err := tx.Cuboids().Update(&moved, "bag_id", "x", "y", "z", "orientation", "version"); err != nil {
+			return err
+		}
+		target.Cuboids = append(target.Cuboids, moved)
+	}
+
+	return nil
+}
+
+// RestoreBag brings back a soft deleted bag with the cuboids that were deleted along with it.
+func (s *Service) RestoreBag(id uint, match Precondition) (models.Bag, error) {
+	var bag models.Bag
+	err := s.store.Transaction(func(tx store.Store) error {
+		if err := tx.Bags().Lock(id); err != nil {
+			return err
+		}
+
+		var err error
+		if bag, err = tx.Bags().Get(id, true); err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				return ErrBagNotFound
+			}
+
+			return err
+		}
+
+		if !bag.DeletedAt.Valid {
+			return ErrNotDeleted
+		}
+
+		if !match.allows(bag.Version) {
+			return ErrVersionMismatch
+		}
+
+		// a cascading delete gives the cuboids the deletion time of the bag
+		if err := tx.Cuboids().RestoreInBag(bag.ID, bag.DeletedAt.Time); err != nil {
+			return err
+		}
+
+		bag.DeletedAt = gorm.DeletedAt{}
+		bag.Version++
+		if err := tx.Bags().Update(&bag, "deleted_at", "version"); err != nil {
+			return err
+		}
+
+		bag, err = loadBag(tx, bag.ID)
+
+		return err
+	})
+
+	return bag, err
+}
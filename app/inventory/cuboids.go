@@ -0,0 +1,303 @@
+package inventory
+
+import (
+	"errors"
+
+	"cuboid-challenge/app/models"
+	"cuboid-challenge/app/store"
+
+	"gorm.io/gorm"
+)
+
+// AddCuboid stores a new cuboid in its bag when the bag admits it. The sizes are checked first,
+// the volume of oversized cuboids can not be computed.
+func (s *Service) AddCuboid(cuboid *models.Cuboid, rotate bool) error {
+	if ok, err := models.Validate(*cuboid); !ok {
+		return err
+	}
+
+	return s.store.Transaction(func(tx store.Store) error {
+		if err := tx.Bags().Lock(cuboid.BagID); err != nil {
+			return err
+		}
+
+		bag, err := loadBag(tx, cuboid.BagID)
+		if err != nil {
+			return err
+		}
+
+		if err := Admits(bag, cuboid, rotate); err != nil {
+			return err
+		}
+
+		return tx.Cuboids().Create(cuboid)
+	})
+}
+
+// NewCuboid is an item of AddCuboids. Err is why the cuboid was rejected, an item that comes with
+// an Err is not checked again but still rejects the batch.
+type NewCuboid struct {
+	Cuboid *models.Cuboid
+	Rotate bool
+	Err    error
+}
+
+// AddCuboids stores a batch of cuboids, for one or several bags, all of them or none. Every cuboid is
+// checked against what its bag has left once the cuboids before it are in. When one is rejected
+// nothing is stored, the items tell why, and the error is ErrCuboidsRejected.
+func (s *Service) AddCuboids(items []NewCuboid) error {
+	return s.store.Transaction(func(tx store.Store) error {
+		bagIDs := make([]uint, len(items))
+		for i := range items {
+			bagIDs[i] = items[i].Cuboid.BagID
+		}
+
+		if err := tx.Bags().Lock(bagIDs...); err != nil {
+			return err
+		}
+
+		// the bags collect the accepted cuboids, so the next ones see the room they take
+		bags := map[uint]*models.Bag{}
+		rejected := false
+		for i := range items {
+			if items[i].Err == nil {
+				items[i].Err = admitInBatch(tx, bags, items[i].Cuboid, items[i].Rotate)
+			}
+			rejected = rejected || items[i].Err != nil
+		}
+
+		if rejected {
+			return ErrCuboidsRejected
+		}
+
+		for i := range items {
+			if err := tx.Cuboids().Create(items[i].Cuboid); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func admitInBatch(tx store.Store, bags map[uint]*models.Bag, cuboid *models.Cuboid, rotate bool) error {
+	if ok, err := models.Validate(*cuboid); !ok {
+		return err
+	}
+
+	bag, ok := bags[cuboid.BagID]
+	if !ok {
+		loaded, err := loadBag(tx, cuboid.BagID)
+		if err != nil {
+			return err
+		}
+		bag = &loaded
+		bags[cuboid.BagID] = bag
+	}
+
+	if err := Admits(*bag, cuboid, rotate); err != nil {
+		return err
+	}
+	bag.Cuboids = append(bag.Cuboids, *cuboid)
+
+	return nil
+}
+
+// CuboidChange is the new state of a cuboid: its sizes and its bag. A zero BagID keeps the bag.
+type CuboidChange struct {
+	Width  uint
+	Height uint
+	Depth  uint
+	BagID  uint
+	Rotate bool
+}
+
+// bagOf returns the bag the cuboid is in after the change.
+func (c CuboidChange) bagOf(cuboid models.Cuboid) uint {
+	if c.BagID != 0 {
+		return c.BagID
+	}
+
+	return cuboid.BagID
+}
+
+// UpdateCuboid resizes the cuboid and, when the change names another bag, moves it there, where it has
+// to be admitted as a new cuboid. The change is built from the cuboid as stored once its bags are locked,
+// so a merge sees the latest state. A failed move leaves the cuboid where it was. A change whose bags
+// keep changing under it fails with ErrConcurrentUpdate.
+func (s *Service) UpdateCuboid(id uint, match Precondition, change func(current models.Cuboid) (CuboidChange, error)) (models.Cuboid, error) {
+	for attempt := 1; attempt <= maxReplans; attempt++ {
+		cuboid, err := s.updateCuboid(id, match, change)
+		if !errors.Is(err, errReplan) {
+			return cuboid, err
+		}
+	}
+
+	return models.Cuboid{}, ErrConcurrentUpdate
+}
+
+// errReplan tells the bags locked for a change are not the ones it involves anymore.
+var errReplan = errors.New("the cuboid changed bags while its update was planned")
+
+// maxReplans bounds the attempts of a change whose bags keep changing under it.
+const maxReplans = 3
+
+// updateCuboid finds the bags the change involves before taking any lock, then locks both of them at
+// once, in the same order as every other transaction, so two opposite moves can not deadlock. Once the
+// locks are held the change is built again, it fails with errReplan if it involves other bags.
+func (s *Service) updateCuboid(id uint, match Precondition, change func(current models.Cuboid) (CuboidChange, error)) (models.Cuboid, error) {
+	current, err := loadCuboid(s.store, id, false)
+	if err != nil {
+		return models.Cuboid{}, err
+	}
+
+	if !match.allows(current.Version) {
+		return models.Cuboid{}, ErrVersionMismatch
+	}
+
+	planned, err := change(current)
+	if err != nil {
+		return models.Cuboid{}, err
+	}
+	source, target := current.BagID, planned.bagOf(current)
+
+	var cuboid models.Cuboid
+	err = s.store.Transaction(func(tx store.Store) error {
+		// the lock is the first statement, the reads after it see the latest state
+		if err := tx.Bags().Lock(source, target); err != nil {
+			return err
+		}
+
+		var err error
+		if cuboid, err = loadCuboid(tx, id, false); err != nil {
+			return err
+		}
+
+		if cuboid.BagID != source {
+			return errReplan
+		}
+
+		if !match.allows(cuboid.Version) {
+			return ErrVersionMismatch
+		}
+
+		c, err := change(cuboid)
+		if err != nil {
+			return err
+		}
+
+		if c.bagOf(cuboid) != target {
+			return errReplan
+		}
+
+		updated := models.Cuboid{Width: c.Width, Height: c.Height, Depth: c.Depth, BagID: target}
+		updated.ID, updated.Version = cuboid.ID, cuboid.Version+1
+
+		if ok, err := models.Validate(updated); !ok {
+			return err
+		}
+
+		if err := admitsUpdate(tx, cuboid, &updated, c.Rotate); err != nil {
+			return err
+		}
+
+		cuboid.Width, cuboid.Height, cuboid.Depth = updated.Width, updated.Height, updated.Depth
+		cuboid.X, cuboid.Y, cuboid.Z = updated.X, updated.Y, updated.Z
+		cuboid.Orientation, cuboid.BagID, cuboid.Version = updated.Orientation, updated.BagID, updated.Version
+
+		return tx.Cuboids().Update(&cuboid, "depth", "height", "width", "x", "y", "z", "orientation", "bag_id", "version")
+	})
+
+	return cuboid, err
+}
+
+// admitsUpdate checks the bag of updated takes it, see UpdateCuboid.
+func admitsUpdate(tx store.Store, cuboid models.Cuboid, updated *models.Cuboid, rotate bool) error {
+	if updated.BagID != cuboid.BagID {
+		target, err := loadBag(tx, updated.BagID)
+		if err != nil {
+			return err
+		}
+
+		return Admits(target, updated, rotate)
+	}
+
+	bag, err := loadBag(tx, cuboid.BagID)
+	if err != nil {
+		return err
+	}
+
+	return admitsResized(bag, cuboid, updated, rotate)
+}
+
+// DeleteCuboid soft deletes the cuboid, its room goes back to the bag.
+func (s *Service) DeleteCuboid(id uint, match Precondition) error {
+	cuboid, err := loadCuboid(s.store, id, false)
+	if err != nil {
+		return err
+	}
+
+	if !match.allows(cuboid.Version) {
+		return ErrVersionMismatch
+	}
+
+	// the version condition turns a concurrent change into a failed precondition
+	deleted, err := s.store.Cuboids().Delete(&cuboid, cuboid.Version)
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return ErrVersionMismatch
+	}
+
+	return nil
+}
+
+// RestoreCuboid brings back a soft deleted cuboid. Its bag must not be deleted and must still have room for it.
+func (s *Service) RestoreCuboid(id uint, match Precondition) (models.Cuboid, error) {
+	// a deleted cuboid keeps its bag, so the bag to lock is known before the transaction
+	deleted, err := loadCuboid(s.store, id, true)
+	if err != nil {
+		return models.Cuboid{}, err
+	}
+
+	var cuboid models.Cuboid
+	err = s.store.Transaction(func(tx store.Store) error {
+		// the lock is the first statement, the reads after it see the latest state
+		if err := tx.Bags().Lock(deleted.BagID); err != nil {
+			return err
+		}
+
+		var err error
+		if cuboid, err = loadCuboid(tx, id, true); err != nil {
+			return err
+		}
+
+		if !cuboid.DeletedAt.Valid {
+			return ErrNotDeleted
+		}
+
+		if !match.allows(cuboid.Version) {
+			return ErrVersionMismatch
+		}
+
+		bag, err := loadBag(tx, cuboid.BagID)
+		if err != nil {
+			return err
+		}
+
+		// the room the cuboid took may have been given to another one since
+		cuboid.X, cuboid.Y, cuboid.Z, cuboid.Orientation = 0, 0, 0, ""
+		if err := Admits(bag, &cuboid, true); err != nil {
+			return err
+		}
+
+		cuboid.DeletedAt = gorm.DeletedAt{}
+		cuboid.Version++
+
+		return tx.Cuboids().Update(&cuboid, "deleted_at", "version", "x", "y", "z", "orientation")
+	})
+
+	return cuboid, err
+}
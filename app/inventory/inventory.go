@@ -0,0 +1,193 @@
+// Package inventory holds the rules bags and cuboids follow: what a bag admits, where a cuboid goes,
+// and how deleting, restoring, disabling and moving affect them. It knows nothing of the transport,
+// the HTTP handlers and the CLI share the same Service.
+package inventory
+
+import (
+	"errors"
+	"fmt"
+
+	"cuboid-challenge/app/models"
+	"cuboid-challenge/app/packing"
+	"cuboid-challenge/app/store"
+)
+
+var (
+	ErrBagNotFound          = errors.New("bag not Found")
+	ErrCuboidNotFound       = errors.New("cuboid not Found")
+	ErrInsufficientCapacity = errors.New("insufficient capacity in bag")
+	ErrBagIsDisabled        = errors.New("bag is disabled")
+	ErrNoRoomInBag          = errors.New("no room in bag for the cuboid")
+	ErrVolumeBelowPayload   = errors.New("volume is smaller than the bag payload")
+	ErrBagNotEmpty          = errors.New("bag is not empty")
+	ErrUnknownDeletePolicy  = errors.New("unknown cuboids policy")
+	ErrInvalidMoveTarget    = errors.New("invalid target bag to move the cuboids to")
+	ErrMoveTargetNotFound   = errors.New("target bag to move the cuboids to not found")
+	ErrNotDeleted           = errors.New("record is not deleted")
+	ErrVersionMismatch      = errors.New("stored version does not match If-Match")
+	ErrCuboidsRejected      = errors.New("some cuboids were rejected")
+	ErrConcurrentUpdate     = errors.New("the cuboid kept changing bags during the update")
+)
+
+// Precondition tells whether a change may apply to a record stored at version, like the If-Match
+// header of the API. A nil Precondition allows every change.
+type Precondition func(version uint) bool
+
+func (p Precondition) allows(version uint) bool {
+	return p == nil || p(version)
+}
+
+// Service applies the rules to the records of a store. Every change runs in a transaction that
+// holds the lock on the bags involved, so the capacity is checked against the latest state.
+type Service struct {
+	store store.Store
+}
+
+// New returns the service on the store.
+func New(s store.Store) *Service {
+	return &Service{store: s}
+}
+
+// loadBag returns the bag with its cuboids, or ErrBagNotFound.
+func loadBag(tx store.Store, bagID uint) (models.Bag, error) {
+	bag, err := tx.Bags().Get(bagID, false)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return models.Bag{}, ErrBagNotFound
+		}
+
+		return models.Bag{}, fmt.Errorf("failed to load bag %d. %w", bagID, err)
+	}
+
+	return bag, nil
+}
+
+// loadCuboid returns the cuboid, or ErrCuboidNotFound.
+func loadCuboid(tx store.Store, cuboidID uint, withDeleted bool) (models.Cuboid, error) {
+	cuboid, err := tx.Cuboids().Get(cuboidID, withDeleted)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return models.Cuboid{}, ErrCuboidNotFound
+		}
+
+		return models.Cuboid{}, fmt.Errorf("failed to load cuboid %d. %w", cuboidID, err)
+	}
+
+	return cuboid, nil
+}
+
+// Admits applies the rules a bag follows to take a new cuboid and places it when the bag has dimensions.
+func Admits(bag models.Bag, cuboid *models.Cuboid, rotate bool) error {
+	if bag.Disabled {
+		return ErrBagIsDisabled
+	}
+
+	available, err := bag.AvailableVolume()
+	if err != nil {
+		return err
+	}
+
+	volume, err := cuboid.PayloadVolume()
+	if err != nil {
+		return err
+	}
+
+	if available < volume {
+		return ErrInsufficientCapacity
+	}
+
+	if !placeInBag(bag, cuboid, rotate) {
+		return ErrNoRoomInBag
+	}
+
+	return nil
+}
+
+// admitsResized checks the bag still holds the cuboid with the sizes of resized, and places it again
+// when the bag has dimensions. A disabled bag keeps the cuboids it has.
+func admitsResized(bag models.Bag, cuboid models.Cuboid, resized *models.Cuboid, rotate bool) error {
+	available, err := bag.AvailableVolume()
+	if err != nil {
+		return err
+	}
+
+	current, err := cuboid.PayloadVolume()
+	if err != nil {
+		return err
+	}
+
+	volume, err := resized.PayloadVolume()
+	if err != nil {
+		return err
+	}
+
+	// the cuboid is part of the payload, the sum is at most the bag volume
+	if available+current < volume {
+		return ErrInsufficientCapacity
+	}
+
+	if !placeInBag(bag, resized, rotate) {
+		return ErrNoRoomInBag
+	}
+
+	return nil
+}
+
+// placeInBag looks for a position for the cuboid around the other cuboids of the bag, leaving out
+// the cuboid itself when it is already in the bag, and stores it in the cuboid.
+// Bags without dimensions only have a volume, so anything that passed the volume check fits.
+func placeInBag(bag models.Bag, cuboid *models.Cuboid, rotate bool) bool {
+	if !bag.HasDimensions() {
+		return true
+	}
+
+	placed, ok := bagPlacements(bag, cuboid.ID)
+	if !ok {
+		return false
+	}
+
+	p, ok := packing.Fit(bag.Dims(), placed, cuboid.Dims(), rotate)
+	if !ok {
+		return false
+	}
+	cuboid.Place(p)
+
+	return true
+}
+
+// bagPlacements returns the room taken by the cuboids of a bag with dimensions, leaving out skipID.
+func bagPlacements(bag models.Bag, skipID uint) ([]packing.Placement, bool) {
+	var placed []packing.Placement
+	var unplaced []packing.Dims
+	for i := range bag.Cuboids {
+		if skipID != 0 && bag.Cuboids[i].ID == skipID {
+			continue
+		}
+
+		if p, ok := bag.Cuboids[i].Placement(); ok {
+			placed = append(placed, p)
+		} else {
+			unplaced = append(unplaced, bag.Cuboids[i].Dims())
+		}
+	}
+
+	// cuboids added before placements were stored still take room somewhere
+	for _, item := range unplaced {
+		p, ok := packing.Fit(bag.Dims(), placed, item, true)
+		if !ok {
+			return nil, false
+		}
+		placed = append(placed, p)
+	}
+
+	return placed, true
+}
+
+func uniqueIDs(ids []uint) map[uint]struct{} {
+	m := make(map[uint]struct{}, len(ids))
+	for _, id := range ids {
+		m[id] = struct{}{}
+	}
+
+	return m
+}
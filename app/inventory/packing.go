@@ -0,0 +1,200 @@
+package inventory
+
+import (
+	"sort"
+
+	"cuboid-challenge/app/models"
+	"cuboid-challenge/app/packing"
+	"cuboid-challenge/app/store"
+)
+
+// Fit is the answer to whether a cuboid fits in a bag. Err is why it does not, Alternatives are the
+// enabled bags that would take it, the tightest fit first.
+type Fit struct {
+	Bag          models.Bag
+	Err          error
+	Alternatives []Alternative
+}
+
+// Alternative is a bag that would take the cuboid, with the volume it has left.
+type Alternative struct {
+	Bag       models.Bag
+	Available uint
+}
+
+// CheckFit tells whether the cuboid would fit in the bag without storing it. The error is about
+// the check itself, like a missing bag, not about the fit.
+func (s *Service) CheckFit(bagID uint, cuboid models.Cuboid, rotate bool) (Fit, error) {
+	if ok, err := models.Validate(cuboid); !ok {
+		return Fit{}, err
+	}
+
+	bag, err := loadBag(s.store, bagID)
+	if err != nil {
+		return Fit{}, err
+	}
+
+	fit := Fit{Bag: bag, Alternatives: []Alternative{}}
+	candidate := models.Cuboid{Width: cuboid.Width, Height: cuboid.Height, Depth: cuboid.Depth}
+	if fit.Err = Admits(bag, &candidate, rotate); fit.Err == nil {
+		return fit, nil
+	}
+
+	others, err := s.store.Bags().Enabled(nil)
+	if err != nil {
+		return Fit{}, err
+	}
+
+	for i := range others {
+		if others[i].ID == bag.ID {
+			continue
+		}
+
+		candidate := models.Cuboid{Width: cuboid.Width, Height: cuboid.Height, Depth: cuboid.Depth}
+		if Admits(others[i], &candidate, rotate) == nil {
+			// Admits already got the available volume without an error
+			available, _ := others[i].AvailableVolume()
+			fit.Alternatives = append(fit.Alternatives, Alternative{others[i], available})
+		}
+	}
+
+	sort.SliceStable(fit.Alternatives, func(i, j int) bool {
+		return fit.Alternatives[i].Available < fit.Alternatives[j].Available
+	})
+
+	return fit, nil
+}
+
+// Assignment is a cuboid of a packing placed in a bag, Index is its position in the items.
+type Assignment struct {
+	Index  int
+	Cuboid *models.Cuboid
+}
+
+// Pack assigns the items across the candidate bags, or every enabled bag when none is given, with
+// the strategy. It returns the assignments and the indexes of the items no bag takes. When commit
+// is set the assigned cuboids are stored, the bags stay locked from the assignment on.
+func (s *Service) Pack(bagIDs []uint, items []packing.Dims, strategy packing.Strategy, rotate, commit bool) ([]Assignment, []int, error) {
+	if !commit {
+		return assignPacking(s.store, bagIDs, nil, items, strategy, rotate)
+	}
+
+	// the lock is the first statement of the transaction, so the enabled bags are read before it
+	locked := bagIDs
+	if len(locked) == 0 {
+		var err error
+		if locked, err = enabledBagIDs(s.store); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var assignments []Assignment
+	var unplaced []int
+	err := s.store.Transaction(func(tx store.Store) error {
+		if err := tx.Bags().Lock(locked...); err != nil {
+			return err
+		}
+
+		var err error
+		if assignments, unplaced, err = assignPacking(tx, bagIDs, locked, items, strategy, rotate); err != nil {
+			return err
+		}
+
+		for i := range assignments {
+			if err := tx.Cuboids().Create(assignments[i].Cuboid); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return assignments, unplaced, err
+}
+
+// assignPacking assigns the items across the candidate bags. When locked is not nil the bags outside
+// of it are left out, they were enabled after the locks were taken.
+func assignPacking(tx store.Store, bagIDs, locked []uint, items []packing.Dims, strategy packing.Strategy, rotate bool) ([]Assignment, []int, error) {
+	bins, err := packingBins(tx, bagIDs, locked)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	assigned, unplaced := packing.Assign(bins, items, strategy, rotate)
+
+	assignments := make([]Assignment, len(assigned))
+	for i, a := range assigned {
+		cuboid := models.Cuboid{Width: items[a.Item].Width, Height: items[a.Item].Height, Depth: items[a.Item].Depth, BagID: a.BinID}
+		if a.Placement.Orientation != "" {
+			cuboid.Place(a.Placement)
+		}
+		assignments[i] = Assignment{Index: a.Item, Cuboid: &cuboid}
+	}
+
+	return assignments, unplaced, nil
+}
+
+func enabledBagIDs(s store.Store) ([]uint, error) {
+	bags, err := s.Bags().Enabled(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, len(bags))
+	for i := range bags {
+		ids[i] = bags[i].ID
+	}
+
+	return ids, nil
+}
+
+// packingBins loads the candidate bags, leaving out the disabled ones, with the room their cuboids take.
+func packingBins(tx store.Store, bagIDs, locked []uint) ([]packing.Bin, error) {
+	if len(bagIDs) > 0 {
+		count, err := tx.Bags().Count(bagIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		if int(count) != len(uniqueIDs(bagIDs)) {
+			return nil, ErrBagNotFound
+		}
+	}
+
+	bags, err := tx.Bags().Enabled(bagIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var only map[uint]bool
+	if locked != nil {
+		only = map[uint]bool{}
+		for _, id := range locked {
+			only[id] = true
+		}
+	}
+
+	bins := make([]packing.Bin, 0, len(bags))
+	for i := range bags {
+		if only != nil && !only[bags[i].ID] {
+			continue
+		}
+
+		used, err := bags[i].PayloadVolume()
+		if err != nil {
+			return nil, err
+		}
+
+		bin := packing.Bin{ID: bags[i].ID, Volume: bags[i].Volume, Used: used}
+		if bags[i].HasDimensions() {
+			placed, ok := bagPlacements(bags[i], 0)
+			if !ok {
+				continue
+			}
+			bin.Dims, bin.Placed = bags[i].Dims(), placed
+		}
+		bins = append(bins, bin)
+	}
+
+	return bins, nil
+}
@@ -3,17 +3,31 @@ package main
 import (
 	"fmt"
 	"log"
+	"net"
 
 	"cuboid-challenge/app/config"
 	"cuboid-challenge/app/db"
 	"cuboid-challenge/app/router"
+	"cuboid-challenge/app/rpc"
+	"cuboid-challenge/app/store"
 )
 
 func main() {
 	config.Load()
-	db.Connect()
+	s := store.NewGorm(db.Connect())
+	r := router.Setup(s)
+
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%s", config.ENV.GRPCPort))
+	if err != nil {
+		log.Fatalln("Failed to listen for the gRPC API.", err)
+	}
+
+	go func() {
+		if err := rpc.NewServer(s).Serve(lis); err != nil {
+			log.Fatalln("Failed to start the gRPC API.", err)
+		}
+	}()
 
-	r := router.Setup()
 	addr := fmt.Sprintf(":%s", config.ENV.Port)
 
 	if err := r.Run(addr); err != nil {
@@ -3,27 +3,86 @@ package models
 import (
 	"encoding/json"
 	"fmt"
+
+	"cuboid-challenge/app/packing"
+	"cuboid-challenge/app/units"
 )
 
 type Bag struct {
 	Model
 
 	Title    string `validate:"required,max=255"`
-	Volume   uint   `validate:"gt=0"`
+	Volume   uint   `validate:"gt=0,max=1000000000000000"`
+	Width    uint   `validate:"max=100000"`
+	Height   uint   `validate:"max=100000"`
+	Depth    uint   `validate:"max=100000"`
 	Disabled bool
 	Cuboids  []Cuboid
+
+	// Unit is the unit the JSON renders the sizes in, the canonical one when empty. It is not stored.
+	Unit units.Unit `gorm:"-"`
 }
 
-func (b *Bag) PayloadVolume() uint {
+// PayloadVolume returns the sum of the cuboids volumes, or packing.ErrVolumeOverflow when it does not fit a uint.
+func (b *Bag) PayloadVolume() (uint, error) {
 	var volumes uint
-	for _, cuboid := range b.Cuboids {
-		volumes += cuboid.PayloadVolume()
+	for i := range b.Cuboids {
+		volume, err := b.Cuboids[i].PayloadVolume()
+		if err != nil {
+			return 0, err
+		}
+
+		if volumes, err = packing.AddVolume(volumes, volume); err != nil {
+			return 0, err
+		}
+	}
+
+	return volumes, nil
+}
+
+// AvailableVolume returns the volume left in the bag, or packing.ErrVolumeUnderflow when the payload is larger.
+func (b *Bag) AvailableVolume() (uint, error) {
+	payload, err := b.PayloadVolume()
+	if err != nil {
+		return 0, err
 	}
-	return volumes
+
+	return packing.SubVolume(b.Volume, payload)
+}
+
+// Dims returns the inner sizes of the bag. They are all zero for bags that only declare a volume.
+func (b *Bag) Dims() packing.Dims {
+	return packing.Dims{Width: b.Width, Height: b.Height, Depth: b.Depth}
+}
+
+// HasDimensions reports whether the bag declares its sizes, so cuboids have to physically fit in it.
+func (b *Bag) HasDimensions() bool {
+	return !b.Dims().IsZero()
 }
 
-func (b *Bag) AvailableVolume() uint {
-	return b.Volume - b.PayloadVolume()
+// Validate checks the dimensions are either all set and match the volume, or not set at all.
+func (b Bag) Validate() (bool, ValidationErrors) {
+	var errs ValidationErrors
+
+	if !b.HasDimensions() {
+		return true, errs
+	}
+
+	dims := []struct {
+		field string
+		value uint
+	}{{"Width", b.Width}, {"Height", b.Height}, {"Depth", b.Depth}}
+	for _, d := range dims {
+		if d.value == 0 {
+			errs = append(errs, FieldError{Field: d.field, Type: "gt", Param: "0"})
+		}
+	}
+
+	if volume, err := b.Dims().Volume(); len(errs) == 0 && (err != nil || volume != b.Volume) {
+		errs = append(errs, FieldError{Field: "Volume", Type: "eqdims"})
+	}
+
+	return len(errs) == 0, errs
 }
 
 func (b *Bag) SetDisabled(value bool) {
@@ -31,15 +90,49 @@ func (b *Bag) SetDisabled(value bool) {
 }
 
 func (b *Bag) MarshalJSON() ([]byte, error) {
+	payload, err := b.PayloadVolume()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Bag. %w", err)
+	}
+
+	available, err := b.AvailableVolume()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Bag. %w", err)
+	}
+
+	unit := b.Unit
+	if unit == "" {
+		unit = units.Canonical
+	}
+
+	// the cuboids render in the unit of their bag
+	cuboids := b.Cuboids
+	if unit != units.Canonical && cuboids != nil {
+		cuboids = make([]Cuboid, len(b.Cuboids))
+		for i := range b.Cuboids {
+			cuboids[i] = b.Cuboids[i]
+			cuboids[i].Unit = unit
+		}
+	}
+
 	j, err := json.Marshal(struct {
-		ID              uint     `json:"id"`
-		Title           string   `json:"title"`
-		Volume          uint     `json:"volume"`
-		PayloadVolume   uint     `json:"payloadVolume"`
-		AvailableVolume uint     `json:"availableVolume"`
-		Cuboids         []Cuboid `json:"cuboids"`
+		ID              uint        `json:"id"`
+		Version         uint        `json:"version"`
+		Title           string      `json:"title"`
+		Volume          json.Number `json:"volume"`
+		Width           json.Number `json:"width"`
+		Height          json.Number `json:"height"`
+		Depth           json.Number `json:"depth"`
+		PayloadVolume   json.Number `json:"payloadVolume"`
+		AvailableVolume json.Number `json:"availableVolume"`
+		Unit            units.Unit  `json:"unit"`
+		Disabled        bool        `json:"disabled"`
+		DeletedAt       *string     `json:"deletedAt"`
+		Cuboids         []Cuboid    `json:"cuboids"`
 	}{
-		b.ID, b.Title, b.Volume, b.PayloadVolume(), b.AvailableVolume(), b.Cuboids,
+		b.ID, b.Version, b.Title, unit.RenderVolume(b.Volume), unit.RenderLength(b.Width), unit.RenderLength(b.Height),
+		unit.RenderLength(b.Depth), unit.RenderVolume(payload), unit.RenderVolume(available), unit, b.Disabled,
+		b.deletedAt(), cuboids,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal Bag. %w", err)
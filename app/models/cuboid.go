@@ -3,33 +3,96 @@ package models
 import (
 	"encoding/json"
 	"fmt"
+
+	"cuboid-challenge/app/packing"
+	"cuboid-challenge/app/units"
 )
 
 type Cuboid struct {
 	Model
 
-	Width  uint `validate:"gt=0"`
-	Height uint `validate:"gt=0"`
-	Depth  uint `validate:"gt=0"`
+	Width  uint `validate:"gt=0,max=100000"`
+	Height uint `validate:"gt=0,max=100000"`
+	Depth  uint `validate:"gt=0,max=100000"`
+
+	// X, Y, Z and Orientation locate the cuboid inside a bag with dimensions.
+	// Orientation is empty while the cuboid has no placement.
+	X           uint
+	Y           uint
+	Z           uint
+	Orientation packing.Orientation
 
 	BagID uint
 	Bag   *Bag
+
+	// Unit is the unit the JSON renders the sizes in, the canonical one when empty. It is not stored.
+	Unit units.Unit `gorm:"-"`
+}
+
+// PayloadVolume returns the volume of the cuboid, or packing.ErrVolumeOverflow for sizes over MaxDimension.
+func (c *Cuboid) PayloadVolume() (uint, error) {
+	return c.Dims().Volume()
+}
+
+// Dims returns the cuboid sizes as given, before any rotation.
+func (c *Cuboid) Dims() packing.Dims {
+	return packing.Dims{Width: c.Width, Height: c.Height, Depth: c.Depth}
+}
+
+// Placement returns where the cuboid sits in its bag, and false when it was never placed.
+func (c *Cuboid) Placement() (packing.Placement, bool) {
+	if c.Orientation == "" {
+		return packing.Placement{}, false
+	}
+
+	return packing.Placement{X: c.X, Y: c.Y, Z: c.Z, Orientation: c.Orientation, Size: c.Orientation.Apply(c.Dims())}, true
+}
+
+// Place stores the position of the cuboid inside its bag.
+func (c *Cuboid) Place(p packing.Placement) {
+	c.X, c.Y, c.Z = p.X, p.Y, p.Z
+	c.Orientation = p.Orientation
 }
 
-func (c *Cuboid) PayloadVolume() uint {
-	return c.Width * c.Height * c.Depth
+type placementJSON struct {
+	X           json.Number `json:"x"`
+	Y           json.Number `json:"y"`
+	Z           json.Number `json:"z"`
+	Orientation string      `json:"orientation"`
 }
 
 func (c *Cuboid) MarshalJSON() ([]byte, error) {
+	unit := c.Unit
+	if unit == "" {
+		unit = units.Canonical
+	}
+
+	var placement *placementJSON
+	if p, ok := c.Placement(); ok {
+		placement = &placementJSON{
+			unit.RenderLength(p.X), unit.RenderLength(p.Y), unit.RenderLength(p.Z), string(p.Orientation),
+		}
+	}
+
+	volume, err := c.PayloadVolume()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Cuboid. %w", err)
+	}
+
 	b, err := json.Marshal(struct {
-		ID     uint `json:"id"`
-		Width  uint `json:"width"`
-		Height uint `json:"height"`
-		Depth  uint `json:"depth"`
-		Volume uint `json:"volume"`
-		BagId  uint `json:"bagId"`
+		ID        uint           `json:"id"`
+		Version   uint           `json:"version"`
+		Width     json.Number    `json:"width"`
+		Height    json.Number    `json:"height"`
+		Depth     json.Number    `json:"depth"`
+		Volume    json.Number    `json:"volume"`
+		Unit      units.Unit     `json:"unit"`
+		BagId     uint           `json:"bagId"`
+		Placement *placementJSON `json:"placement"`
+		DeletedAt *string        `json:"deletedAt"`
 	}{
-		c.ID, c.Width, c.Height, c.Depth, c.PayloadVolume(), c.BagID,
+		c.ID, c.Version, unit.RenderLength(c.Width), unit.RenderLength(c.Height), unit.RenderLength(c.Depth),
+		unit.RenderVolume(volume), unit, c.BagID, placement, c.deletedAt(),
 	})
 	if err != nil {
 		err = fmt.Errorf("failed to marshal Cuboid. %w", err)
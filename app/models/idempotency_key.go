@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// IdempotencyKey records the response to a request sent with an Idempotency-Key header, so a retry
+// gets the same response instead of repeating the request. Status is zero while the request runs.
+// ContentType is the one of the response, a problem document is replayed as one.
+type IdempotencyKey struct {
+	Key         string `gorm:"primarykey;size:255"`
+	RequestHash string `gorm:"size:64;not null"`
+	Status      int    `gorm:"not null;default:0"`
+	ETag        string `gorm:"size:64"`
+	ContentType string `gorm:"size:255"`
+	Body        []byte
+	CreatedAt   time.Time `gorm:"index"`
+}
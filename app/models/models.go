@@ -2,35 +2,105 @@ package models
 
 import (
 	"errors"
-	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"cuboid-challenge/app/i18n"
 
 	"github.com/go-playground/validator"
+	"gorm.io/gorm"
+)
+
+// MaxDimension and MaxVolume bound the sizes the API takes, the `validate` tags repeat them.
+// Volumes up to MaxVolume stay exact as JSON numbers, which clients may read as doubles, and fit
+// the signed 64 bit integer columns of every database. The fields stay uint, within these bounds.
+const (
+	MaxDimension = 100_000
+	MaxVolume    = MaxDimension * MaxDimension * MaxDimension
 )
 
 // Model is the base definition for every app model.
+// Version starts at 1 and goes up with every update, it is the record ETag.
+// DeletedAt makes deletes soft, gorm hides the deleted records unless the query is Unscoped.
 type Model struct {
-	ID uint `gorm:"primarykey"`
+	ID        uint           `gorm:"primarykey"`
+	Version   uint           `gorm:"not null;default:1"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+// BeforeCreate sets the first version, the database default is not read back on insert.
+func (m *Model) BeforeCreate(tx *gorm.DB) error {
+	if m.Version == 0 {
+		m.Version = 1
+	}
+
+	return nil
+}
+
+// deletedAt formats the deletion time for JSON, it is nil while the record is not deleted.
+func (m *Model) deletedAt() *string {
+	if !m.DeletedAt.Valid {
+		return nil
+	}
+
+	t := m.DeletedAt.Time.UTC().Format(time.RFC3339)
+
+	return &t
 }
 
 // FieldError is an error used to indicate there is field model validation error.
+// Message is the fallback for the types the message catalog does not know.
 type FieldError struct {
 	Field   string
 	Type    string
 	Param   string
 	Message string
+
+	// text tells the field is a string, its size tags are about the length
+	text bool
 }
 
-// Error returns an humanized error message.
+// Error returns an humanized error message in the default locale.
 func (fe *FieldError) Error() string {
+	return fe.Localize(i18n.Default)
+}
+
+// Localize returns the message in the given locale, naming the field as it is in the API JSON.
+func (fe *FieldError) Localize(loc i18n.Locale) string {
+	if len(fe.Field+fe.Type) == 0 {
+		return "Unknown error"
+	}
+
+	keys := []string{fe.Type}
+	if fe.text {
+		keys = []string{fe.Type + ".string", fe.Type}
+	}
+
+	if msg, ok := i18n.FieldMessage(loc, JSONName(fe.Field), fe.Param, keys...); ok {
+		return msg
+	}
+
 	if len(fe.Message) > 0 {
 		return fe.Message
 	}
 
-	if len(fe.Field+fe.Type) == 0 {
-		return "Unknown error"
+	msg, _ := i18n.FieldMessage(loc, JSONName(fe.Field), fe.Param, "invalid")
+
+	return msg
+}
+
+// JSONName returns the name of a model field in the API JSON, `BagID` is `bagId`.
+func JSONName(field string) string {
+	if field == "" {
+		return field
 	}
 
-	return fmt.Sprintf("Error:Field validation for '%s' failed on the '%s' tag", fe.Field, fe.Type)
+	if strings.HasSuffix(field, "ID") {
+		field = strings.TrimSuffix(field, "ID") + "Id"
+	}
+
+	return strings.ToLower(field[:1]) + field[1:]
 }
 
 // ValidationErrors is an error used to indicate there is model validation error.
@@ -38,13 +108,18 @@ type ValidationErrors []FieldError
 
 // Error concatenates all the contained field errors messages.
 func (v ValidationErrors) Error() string {
+	return v.Localize(i18n.Default)
+}
+
+// Localize concatenates all the contained field errors messages in the given locale.
+func (v ValidationErrors) Localize(loc i18n.Locale) string {
 	r := ""
-	for _, e := range v {
+	for i := range v {
 		if len(r) > 0 {
 			r += "\n"
 		}
 
-		r += e.Error()
+		r += v[i].Localize(loc)
 	}
 
 	return r
@@ -70,6 +145,7 @@ func Validate(record interface{}) (bool, ValidationErrors) {
 					Field: v.Field(),
 					Type:  v.Tag(),
 					Param: v.Param(),
+					text:  v.Kind() == reflect.String,
 				})
 			}
 		} else {
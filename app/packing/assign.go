@@ -0,0 +1,127 @@
+package packing
+
+import "sort"
+
+// Strategy is the heuristic used to choose a bin for every item.
+type Strategy string
+
+const (
+	// FirstFitDecreasing puts every item, biggest first, in the first bin with room for it.
+	FirstFitDecreasing Strategy = "first-fit-decreasing"
+	// BestFitDecreasing puts every item, biggest first, in the bin left with the least free volume.
+	BestFitDecreasing Strategy = "best-fit-decreasing"
+)
+
+// IsValid reports whether s is a known strategy.
+func (s Strategy) IsValid() bool {
+	return s == FirstFitDecreasing || s == BestFitDecreasing
+}
+
+// Bin is a container items can be assigned to. Bins with zero Dims only limit the volume.
+type Bin struct {
+	ID     uint
+	Volume uint
+	Dims   Dims
+	Used   uint
+	Placed []Placement
+}
+
+func (b *Bin) free() uint {
+	if b.Used > b.Volume {
+		return 0
+	}
+
+	return b.Volume - b.Used
+}
+
+func (b *Bin) fit(item Dims, volume uint, rotate bool) (Placement, bool) {
+	if b.free() < volume {
+		return Placement{}, false
+	}
+
+	if b.Dims.IsZero() {
+		return Placement{}, true
+	}
+
+	return Fit(b.Dims, b.Placed, item, rotate)
+}
+
+func (b *Bin) add(volume uint, p Placement) {
+	// fit checked the volume is free, the sum stays below Volume
+	b.Used += volume
+	if !b.Dims.IsZero() {
+		b.Placed = append(b.Placed, p)
+	}
+}
+
+// Assignment tells which bin an item, referenced by its index in the input, went to.
+// Placement is only meaningful when the bin has dimensions.
+type Assignment struct {
+	Item      int
+	BinID     uint
+	Placement Placement
+}
+
+// Assign distributes items across bins with the given strategy. It returns the assignments
+// ordered by item index and the indexes of the items no bin could take.
+// The bins are updated with the room taken by the assigned items. Items with a volume that overflows are unplaced.
+func Assign(bins []Bin, items []Dims, strategy Strategy, rotate bool) ([]Assignment, []int) {
+	var assignments []Assignment
+	var unplaced []int
+
+	volumes := make([]uint, len(items))
+	order := make([]int, 0, len(items))
+	for i := range items {
+		v, err := items[i].Volume()
+		if err != nil {
+			unplaced = append(unplaced, i)
+
+			continue
+		}
+		volumes[i] = v
+		order = append(order, i)
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return volumes[order[i]] > volumes[order[j]]
+	})
+
+	for _, i := range order {
+		bin, p, ok := chooseBin(bins, items[i], volumes[i], strategy, rotate)
+		if !ok {
+			unplaced = append(unplaced, i)
+
+			continue
+		}
+
+		bin.add(volumes[i], p)
+		assignments = append(assignments, Assignment{Item: i, BinID: bin.ID, Placement: p})
+	}
+
+	sort.Slice(assignments, func(i, j int) bool { return assignments[i].Item < assignments[j].Item })
+	sort.Ints(unplaced)
+
+	return assignments, unplaced
+}
+
+func chooseBin(bins []Bin, item Dims, volume uint, strategy Strategy, rotate bool) (*Bin, Placement, bool) {
+	var best *Bin
+	var bestPlacement Placement
+
+	for i := range bins {
+		p, ok := bins[i].fit(item, volume, rotate)
+		if !ok {
+			continue
+		}
+
+		if strategy != BestFitDecreasing {
+			return &bins[i], p, true
+		}
+
+		if best == nil || bins[i].free() < best.free() {
+			best, bestPlacement = &bins[i], p
+		}
+	}
+
+	return best, bestPlacement, best != nil
+}
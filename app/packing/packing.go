@@ -0,0 +1,164 @@
+package packing
+
+import "sort"
+
+// Dims are the sizes of a box along the X (width), Y (height) and Z (depth) axes.
+type Dims struct {
+	Width  uint
+	Height uint
+	Depth  uint
+}
+
+// IsZero reports whether no dimension was set.
+func (d Dims) IsZero() bool {
+	return d.Width == 0 && d.Height == 0 && d.Depth == 0
+}
+
+// Volume returns the product of the three dimensions, or ErrVolumeOverflow when it does not fit a uint.
+func (d Dims) Volume() (uint, error) {
+	v, err := MulVolume(d.Width, d.Height)
+	if err != nil {
+		return 0, err
+	}
+
+	return MulVolume(v, d.Depth)
+}
+
+// Orientation tells which box dimension lies along the X, Y and Z axes of the container.
+// "WHD" is the box as given, "DHW" is the box turned 90° around the Y axis, and so on.
+type Orientation string
+
+const (
+	OrientationWHD Orientation = "WHD"
+	OrientationWDH Orientation = "WDH"
+	OrientationHWD Orientation = "HWD"
+	OrientationHDW Orientation = "HDW"
+	OrientationDWH Orientation = "DWH"
+	OrientationDHW Orientation = "DHW"
+)
+
+var allOrientations = []Orientation{
+	OrientationWHD, OrientationWDH, OrientationHWD, OrientationHDW, OrientationDWH, OrientationDHW,
+}
+
+// Orientations returns the orientations a box may take. Without rotation only the given one is allowed.
+func Orientations(rotate bool) []Orientation {
+	if !rotate {
+		return allOrientations[:1]
+	}
+
+	return allOrientations
+}
+
+// Apply returns the box sizes along the container axes once the box is turned to o.
+func (o Orientation) Apply(d Dims) Dims {
+	pick := func(c byte) uint {
+		switch c {
+		case 'H':
+			return d.Height
+		case 'D':
+			return d.Depth
+		default:
+			return d.Width
+		}
+	}
+
+	if len(o) != 3 {
+		return d
+	}
+
+	return Dims{Width: pick(o[0]), Height: pick(o[1]), Depth: pick(o[2])}
+}
+
+// Placement is the position of a box inside a container. X, Y and Z are the offsets of the
+// box corner closest to the container origin, Size is the box once turned to Orientation.
+type Placement struct {
+	X, Y, Z     uint
+	Orientation Orientation
+	Size        Dims
+}
+
+func (p Placement) overlaps(q Placement) bool {
+	return p.X < q.X+q.Size.Width && q.X < p.X+p.Size.Width &&
+		p.Y < q.Y+q.Size.Height && q.Y < p.Y+p.Size.Height &&
+		p.Z < q.Z+q.Size.Depth && q.Z < p.Z+p.Size.Depth
+}
+
+func (p Placement) within(container Dims) bool {
+	return p.X+p.Size.Width <= container.Width &&
+		p.Y+p.Size.Height <= container.Height &&
+		p.Z+p.Size.Depth <= container.Depth
+}
+
+// Fit looks for a free position for item inside container next to the already placed boxes.
+// Candidate positions are the container origin and the corners touching the placed boxes,
+// tried from the bottom up, so boxes are stacked the way a person would fill a bag.
+func Fit(container Dims, placed []Placement, item Dims, rotate bool) (Placement, bool) {
+	for _, point := range candidatePoints(placed) {
+		for _, o := range Orientations(rotate) {
+			p := Placement{X: point.X, Y: point.Y, Z: point.Z, Orientation: o, Size: o.Apply(item)}
+			if !p.within(container) {
+				continue
+			}
+
+			if !overlapsAny(p, placed) {
+				return p, true
+			}
+		}
+	}
+
+	return Placement{}, false
+}
+
+// Pack places items one after another in the given order. It reports false when any item has no room.
+func Pack(container Dims, items []Dims, rotate bool) ([]Placement, bool) {
+	placed := make([]Placement, 0, len(items))
+	for _, item := range items {
+		p, ok := Fit(container, placed, item, rotate)
+		if !ok {
+			return nil, false
+		}
+
+		placed = append(placed, p)
+	}
+
+	return placed, true
+}
+
+func overlapsAny(p Placement, placed []Placement) bool {
+	for _, q := range placed {
+		if p.overlaps(q) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type point struct{ X, Y, Z uint }
+
+func candidatePoints(placed []Placement) []point {
+	points := []point{{0, 0, 0}}
+	for _, p := range placed {
+		points = append(points,
+			point{p.X + p.Size.Width, p.Y, p.Z},
+			point{p.X, p.Y + p.Size.Height, p.Z},
+			point{p.X, p.Y, p.Z + p.Size.Depth},
+		)
+	}
+
+	// bottom first, then back to front, then left to right
+	sort.SliceStable(points, func(i, j int) bool {
+		a, b := points[i], points[j]
+		if a.Y != b.Y {
+			return a.Y < b.Y
+		}
+		if a.Z != b.Z {
+			return a.Z < b.Z
+		}
+
+		return a.X < b.X
+	})
+
+	return points
+}
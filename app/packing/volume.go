@@ -0,0 +1,41 @@
+package packing
+
+import (
+	"errors"
+	"math/bits"
+)
+
+var (
+	ErrVolumeOverflow  = errors.New("volume overflow")
+	ErrVolumeUnderflow = errors.New("volume underflow")
+)
+
+// MulVolume returns a * b, or ErrVolumeOverflow when the product does not fit a uint.
+func MulVolume(a, b uint) (uint, error) {
+	hi, lo := bits.Mul(a, b)
+	if hi != 0 {
+		return 0, ErrVolumeOverflow
+	}
+
+	return lo, nil
+}
+
+// AddVolume returns a + b, or ErrVolumeOverflow when the sum does not fit a uint.
+func AddVolume(a, b uint) (uint, error) {
+	sum, carry := bits.Add(a, b, 0)
+	if carry != 0 {
+		return 0, ErrVolumeOverflow
+	}
+
+	return sum, nil
+}
+
+// SubVolume returns a - b, or ErrVolumeUnderflow when b is larger than a.
+func SubVolume(a, b uint) (uint, error) {
+	diff, borrow := bits.Sub(a, b, 0)
+	if borrow != 0 {
+		return 0, ErrVolumeUnderflow
+	}
+
+	return diff, nil
+}
@@ -4,13 +4,16 @@ import (
 	"net/http"
 
 	"cuboid-challenge/app/controller"
+	"cuboid-challenge/app/graph"
+	"cuboid-challenge/app/store"
 
 	"github.com/gin-gonic/gin"
 )
 
-// Setup returns the app router.
-func Setup() *gin.Engine {
+// Setup returns the app router, its handlers read and write the records through the store.
+func Setup(s store.Store) *gin.Engine {
 	r := gin.Default()
+	h := controller.New(s)
 
 	r.GET("/ping", func(c *gin.Context) {
 		c.String(http.StatusOK, "pong")
@@ -18,20 +21,45 @@ func Setup() *gin.Engine {
 
 	bag := r.Group("/bags")
 	{
-		bag.GET("", controller.ListBags)
-		bag.GET("/:bagID", controller.GetBag)
-		bag.POST("", controller.CreateBag)
-		bag.DELETE("/:bagID", controller.DeleteBag)
+		bag.GET("", h.ListBags)
+		bag.GET("/:bagID", h.GetBag)
+		bag.GET("/:bagID/fit", h.GetBagFit)
+		bag.POST("", h.Idempotent, h.CreateBag)
+		bag.PUT("/:bagID", h.UpdateBag)
+		bag.PATCH("/:bagID", h.UpdateBag)
+		bag.DELETE("/:bagID", h.DeleteBag)
+		bag.POST("/:bagID/restore", h.RestoreBag)
+	}
+
+	bagCuboid := r.Group("/bags/:bagID/cuboids", h.BagScope)
+	{
+		bagCuboid.GET("", h.ListCuboids)
+		bagCuboid.GET("/:cuboidID", h.GetCuboid)
+		bagCuboid.POST("", h.Idempotent, h.CreateCuboid)
+		bagCuboid.PUT("/:cuboidID", h.UpdateCuboid)
+		bagCuboid.PATCH("/:cuboidID", h.PatchCuboid)
+		bagCuboid.DELETE("/:cuboidID", h.DeleteCuboid)
 	}
 
 	cuboid := r.Group("/cuboids")
 	{
-		cuboid.GET("", controller.ListCuboids)
-		cuboid.GET("/:cuboidID", controller.GetCuboid)
-		cuboid.POST("", controller.CreateCuboid)
-		cuboid.PUT("/:cuboidID", controller.UpdateCuboid)
-		cuboid.DELETE("/:cuboidID", controller.DeleteCuboid)
+		cuboid.GET("", h.ListCuboids)
+		cuboid.GET("/:cuboidID", h.GetCuboid)
+		cuboid.POST("", h.Idempotent, h.CreateCuboid)
+		cuboid.POST("/bulk", h.Idempotent, h.CreateCuboids)
+		cuboid.DELETE("/bulk", h.DeleteCuboids)
+		cuboid.PUT("/:cuboidID", h.UpdateCuboid)
+		cuboid.PATCH("/:cuboidID", h.PatchCuboid)
+		cuboid.DELETE("/:cuboidID", h.DeleteCuboid)
+		cuboid.POST("/:cuboidID/restore", h.RestoreCuboid)
 	}
 
+	r.POST("/packings", h.Idempotent, h.CreatePacking)
+
+	r.POST("/graphql", graph.Handler(s))
+
+	r.GET("/problems", controller.ListProblems)
+	r.GET("/problems/:code", controller.GetProblem)
+
 	return r
 }
@@ -0,0 +1,144 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+
+	"cuboid-challenge/app/api"
+	"cuboid-challenge/app/inventory"
+	"cuboid-challenge/app/models"
+	"cuboid-challenge/app/rpc/pb"
+)
+
+// bagError reports an error of a request whose resource is the bag, a missing one is not found
+// rather than a bag the request refers to, like in the REST API.
+func bagError(err error) error {
+	if errors.Is(err, inventory.ErrBagNotFound) {
+		err = api.ErrNotFound
+	}
+
+	return statusError(err)
+}
+
+func (s *server) ListBags(_ context.Context, req *pb.ListRequest) (*pb.ListBagsResponse, error) {
+	q, err := api.BagList.Parse(listParams(req))
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	bags, total, cursor, err := s.store.Bags().List(q)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	next, err := api.EncodeCursor(q, cursor)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	res := &pb.ListBagsResponse{Bags: make([]*pb.Bag, len(bags)), Total: total, NextCursor: next}
+	for i := range bags {
+		if res.Bags[i], err = bagMessage(&bags[i]); err != nil {
+			return nil, statusError(err)
+		}
+	}
+
+	return res, nil
+}
+
+func (s *server) GetBag(_ context.Context, req *pb.GetRequest) (*pb.Bag, error) {
+	bag, err := s.store.Bags().Get(uint(req.Id), req.IncludeDeleted)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return bagResponse(bag)
+}
+
+// CreateBag creates an empty bag, its volume is the one of the dimensions when left out.
+func (s *server) CreateBag(_ context.Context, req *pb.CreateBagRequest) (*pb.Bag, error) {
+	bag := models.Bag{
+		Title:   req.Title,
+		Volume:  uint(req.Volume),
+		Width:   uint(req.Width),
+		Height:  uint(req.Height),
+		Depth:   uint(req.Depth),
+		Cuboids: []models.Cuboid{},
+	}
+	if err := s.inventory.CreateBag(&bag); err != nil {
+		return nil, statusError(err)
+	}
+
+	return bagResponse(bag)
+}
+
+// UpdateBag changes the fields set in the request, see inventory.Service.UpdateBag for the rules.
+func (s *server) UpdateBag(_ context.Context, req *pb.UpdateBagRequest) (*pb.Bag, error) {
+	change := inventory.BagChange{Title: req.Title, Disabled: req.Disabled}
+	if req.Volume != nil {
+		volume := uint(*req.Volume)
+		change.Volume = &volume
+	}
+
+	bag, err := s.inventory.UpdateBag(uint(req.Id), expectVersion(req.ExpectedVersion), change)
+	if err != nil {
+		return nil, bagError(err)
+	}
+
+	return bagResponse(bag)
+}
+
+// DeleteBag deletes the bag and applies the policy of the request to its cuboids, see DeleteBagRequest.
+func (s *server) DeleteBag(_ context.Context, req *pb.DeleteBagRequest) (*pb.DeleteResponse, error) {
+	policy := inventory.DeletePolicy(req.Cuboids)
+	if policy == "" {
+		policy = inventory.DeleteRefuse
+	}
+
+	if policy == inventory.DeleteMove && req.TargetBagId == 0 {
+		return nil, statusError(inventory.ErrInvalidMoveTarget)
+	}
+
+	err := s.inventory.DeleteBag(uint(req.Id), expectVersion(req.ExpectedVersion), policy, uint(req.TargetBagId))
+	if err != nil {
+		return nil, bagError(err)
+	}
+
+	return &pb.DeleteResponse{}, nil
+}
+
+// CheckFit answers whether a cuboid would fit in the bag without creating it, with the bags that
+// could take it when it does not.
+func (s *server) CheckFit(_ context.Context, req *pb.CheckFitRequest) (*pb.CheckFitResponse, error) {
+	cuboid := models.Cuboid{Width: uint(req.Width), Height: uint(req.Height), Depth: uint(req.Depth)}
+
+	fit, err := s.inventory.CheckFit(uint(req.BagId), cuboid, req.Rotate)
+	if err != nil {
+		return nil, bagError(err)
+	}
+
+	res := &pb.CheckFitResponse{
+		BagId:        uint64(fit.Bag.ID),
+		Fits:         fit.Err == nil,
+		Alternatives: make([]*pb.FitAlternative, len(fit.Alternatives)),
+	}
+	if fit.Err != nil {
+		// the reason is the title of the problem, as in the REST answer
+		_, res.Reason = api.ProblemFor(fit.Err)
+	}
+
+	for i, a := range fit.Alternatives {
+		res.Alternatives[i] = &pb.FitAlternative{Id: uint64(a.Bag.ID), Title: a.Bag.Title, AvailableVolume: uint64(a.Available)}
+	}
+
+	return res, nil
+}
+
+func bagResponse(bag models.Bag) (*pb.Bag, error) {
+	m, err := bagMessage(&bag)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return m, nil
+}
@@ -0,0 +1,99 @@
+package rpc
+
+import (
+	"context"
+
+	"cuboid-challenge/app/api"
+	"cuboid-challenge/app/inventory"
+	"cuboid-challenge/app/models"
+	"cuboid-challenge/app/rpc/pb"
+)
+
+func (s *server) ListCuboids(_ context.Context, req *pb.ListRequest) (*pb.ListCuboidsResponse, error) {
+	q, err := api.CuboidList.Parse(listParams(req))
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	cuboids, total, cursor, err := s.store.Cuboids().List(q)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	next, err := api.EncodeCursor(q, cursor)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	res := &pb.ListCuboidsResponse{Cuboids: make([]*pb.Cuboid, len(cuboids)), Total: total, NextCursor: next}
+	for i := range cuboids {
+		if res.Cuboids[i], err = cuboidMessage(&cuboids[i]); err != nil {
+			return nil, statusError(err)
+		}
+	}
+
+	return res, nil
+}
+
+func (s *server) GetCuboid(_ context.Context, req *pb.GetRequest) (*pb.Cuboid, error) {
+	cuboid, err := s.store.Cuboids().Get(uint(req.Id), req.IncludeDeleted)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return cuboidResponse(cuboid)
+}
+
+// CreateCuboid creates a cuboid in a bag that admits it.
+func (s *server) CreateCuboid(_ context.Context, req *pb.CreateCuboidRequest) (*pb.Cuboid, error) {
+	cuboid := models.Cuboid{
+		Width:  uint(req.Width),
+		Height: uint(req.Height),
+		Depth:  uint(req.Depth),
+		BagID:  uint(req.BagId),
+	}
+
+	if err := s.inventory.AddCuboid(&cuboid, req.Rotate); err != nil {
+		return nil, statusError(err)
+	}
+
+	return cuboidResponse(cuboid)
+}
+
+// UpdateCuboid resizes the cuboid and, when bag_id names another bag, moves it there.
+// A failed move leaves the cuboid where it was.
+func (s *server) UpdateCuboid(_ context.Context, req *pb.UpdateCuboidRequest) (*pb.Cuboid, error) {
+	change := inventory.CuboidChange{
+		Width:  uint(req.Width),
+		Height: uint(req.Height),
+		Depth:  uint(req.Depth),
+		BagID:  uint(req.BagId),
+		Rotate: req.Rotate,
+	}
+
+	cuboid, err := s.inventory.UpdateCuboid(uint(req.Id), expectVersion(req.ExpectedVersion), func(models.Cuboid) (inventory.CuboidChange, error) {
+		return change, nil
+	})
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return cuboidResponse(cuboid)
+}
+
+func (s *server) DeleteCuboid(_ context.Context, req *pb.DeleteCuboidRequest) (*pb.DeleteResponse, error) {
+	if err := s.inventory.DeleteCuboid(uint(req.Id), expectVersion(req.ExpectedVersion)); err != nil {
+		return nil, statusError(err)
+	}
+
+	return &pb.DeleteResponse{}, nil
+}
+
+func cuboidResponse(cuboid models.Cuboid) (*pb.Cuboid, error) {
+	m, err := cuboidMessage(&cuboid)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return m, nil
+}
@@ -0,0 +1,65 @@
+package rpc
+
+import (
+	"cuboid-challenge/app/models"
+	"cuboid-challenge/app/rpc/pb"
+)
+
+func bagMessage(bag *models.Bag) (*pb.Bag, error) {
+	payload, err := bag.PayloadVolume()
+	if err != nil {
+		return nil, err
+	}
+
+	available, err := bag.AvailableVolume()
+	if err != nil {
+		return nil, err
+	}
+
+	m := &pb.Bag{
+		Id:              uint64(bag.ID),
+		Version:         uint64(bag.Version),
+		Title:           bag.Title,
+		Volume:          uint64(bag.Volume),
+		Width:           uint64(bag.Width),
+		Height:          uint64(bag.Height),
+		Depth:           uint64(bag.Depth),
+		PayloadVolume:   uint64(payload),
+		AvailableVolume: uint64(available),
+		Disabled:        bag.Disabled,
+		Deleted:         bag.DeletedAt.Valid,
+		Cuboids:         make([]*pb.Cuboid, len(bag.Cuboids)),
+	}
+
+	for i := range bag.Cuboids {
+		if m.Cuboids[i], err = cuboidMessage(&bag.Cuboids[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+func cuboidMessage(cuboid *models.Cuboid) (*pb.Cuboid, error) {
+	volume, err := cuboid.PayloadVolume()
+	if err != nil {
+		return nil, err
+	}
+
+	m := &pb.Cuboid{
+		Id:      uint64(cuboid.ID),
+		Version: uint64(cuboid.Version),
+		Width:   uint64(cuboid.Width),
+		Height:  uint64(cuboid.Height),
+		Depth:   uint64(cuboid.Depth),
+		Volume:  uint64(volume),
+		BagId:   uint64(cuboid.BagID),
+		Deleted: cuboid.DeletedAt.Valid,
+	}
+
+	if p, ok := cuboid.Placement(); ok {
+		m.Placement = &pb.Placement{X: uint64(p.X), Y: uint64(p.Y), Z: uint64(p.Z), Orientation: string(p.Orientation)}
+	}
+
+	return m, nil
+}
@@ -0,0 +1,483 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: inventory.proto
+
+// The gRPC API of the bags and cuboids. It follows the REST API: the same records, the same rules and
+// the same errors, reported with the gRPC status matching the HTTP one. Every size is in the canonical
+// unit, millimetres, and volumes in its cube.
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Inventory_ListBags_FullMethodName     = "/cuboid.v1.Inventory/ListBags"
+	Inventory_GetBag_FullMethodName       = "/cuboid.v1.Inventory/GetBag"
+	Inventory_CreateBag_FullMethodName    = "/cuboid.v1.Inventory/CreateBag"
+	Inventory_UpdateBag_FullMethodName    = "/cuboid.v1.Inventory/UpdateBag"
+	Inventory_DeleteBag_FullMethodName    = "/cuboid.v1.Inventory/DeleteBag"
+	Inventory_CheckFit_FullMethodName     = "/cuboid.v1.Inventory/CheckFit"
+	Inventory_ListCuboids_FullMethodName  = "/cuboid.v1.Inventory/ListCuboids"
+	Inventory_GetCuboid_FullMethodName    = "/cuboid.v1.Inventory/GetCuboid"
+	Inventory_CreateCuboid_FullMethodName = "/cuboid.v1.Inventory/CreateCuboid"
+	Inventory_UpdateCuboid_FullMethodName = "/cuboid.v1.Inventory/UpdateCuboid"
+	Inventory_DeleteCuboid_FullMethodName = "/cuboid.v1.Inventory/DeleteCuboid"
+)
+
+// InventoryClient is the client API for Inventory service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type InventoryClient interface {
+	ListBags(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListBagsResponse, error)
+	GetBag(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Bag, error)
+	CreateBag(ctx context.Context, in *CreateBagRequest, opts ...grpc.CallOption) (*Bag, error)
+	UpdateBag(ctx context.Context, in *UpdateBagRequest, opts ...grpc.CallOption) (*Bag, error)
+	DeleteBag(ctx context.Context, in *DeleteBagRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	CheckFit(ctx context.Context, in *CheckFitRequest, opts ...grpc.CallOption) (*CheckFitResponse, error)
+	ListCuboids(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListCuboidsResponse, error)
+	GetCuboid(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Cuboid, error)
+	CreateCuboid(ctx context.Context, in *CreateCuboidRequest, opts ...grpc.CallOption) (*Cuboid, error)
+	UpdateCuboid(ctx context.Context, in *UpdateCuboidRequest, opts ...grpc.CallOption) (*Cuboid, error)
+	DeleteCuboid(ctx context.Context, in *DeleteCuboidRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+}
+
+type inventoryClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewInventoryClient(cc grpc.ClientConnInterface) InventoryClient {
+	return &inventoryClient{cc}
+}
+
+func (c *inventoryClient) ListBags(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListBagsResponse, error) {
+	out := new(ListBagsResponse)
+	err := c.cc.Invoke(ctx, Inventory_ListBags_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryClient) GetBag(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Bag, error) {
+	out := new(Bag)
+	err := c.cc.Invoke(ctx, Inventory_GetBag_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryClient) CreateBag(ctx context.Context, in *CreateBagRequest, opts ...grpc.CallOption) (*Bag, error) {
+	out := new(Bag)
+	err := c.cc.Invoke(ctx, Inventory_CreateBag_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryClient) UpdateBag(ctx context.Context, in *UpdateBagRequest, opts ...grpc.CallOption) (*Bag, error) {
+	out := new(Bag)
+	err := c.cc.Invoke(ctx, Inventory_UpdateBag_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryClient) DeleteBag(ctx context.Context, in *DeleteBagRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
+	out := new(DeleteResponse)
+	err := c.cc.Invoke(ctx, Inventory_DeleteBag_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryClient) CheckFit(ctx context.Context, in *CheckFitRequest, opts ...grpc.CallOption) (*CheckFitResponse, error) {
+	out := new(CheckFitResponse)
+	err := c.cc.Invoke(ctx, Inventory_CheckFit_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryClient) ListCuboids(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListCuboidsResponse, error) {
+	out := new(ListCuboidsResponse)
+	err := c.cc.Invoke(ctx, Inventory_ListCuboids_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryClient) GetCuboid(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Cuboid, error) {
+	out := new(Cuboid)
+	err := c.cc.Invoke(ctx, Inventory_GetCuboid_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryClient) CreateCuboid(ctx context.Context, in *CreateCuboidRequest, opts ...grpc.CallOption) (*Cuboid, error) {
+	out := new(Cuboid)
+	err := c.cc.Invoke(ctx, Inventory_CreateCuboid_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryClient) UpdateCuboid(ctx context.Context, in *UpdateCuboidRequest, opts ...grpc.CallOption) (*Cuboid, error) {
+	out := new(Cuboid)
+	err := c.cc.Invoke(ctx, Inventory_UpdateCuboid_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryClient) DeleteCuboid(ctx context.Context, in *DeleteCuboidRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
+	out := new(DeleteResponse)
+	err := c.cc.Invoke(ctx, Inventory_DeleteCuboid_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// InventoryServer is the server API for Inventory service.
+// All implementations must embed UnimplementedInventoryServer
+// for forward compatibility
+type InventoryServer interface {
+	ListBags(context.Context, *ListRequest) (*ListBagsResponse, error)
+	GetBag(context.Context, *GetRequest) (*Bag, error)
+	CreateBag(context.Context, *CreateBagRequest) (*Bag, error)
+	UpdateBag(context.Context, *UpdateBagRequest) (*Bag, error)
+	DeleteBag(context.Context, *DeleteBagRequest) (*DeleteResponse, error)
+	CheckFit(context.Context, *CheckFitRequest) (*CheckFitResponse, error)
+	ListCuboids(context.Context, *ListRequest) (*ListCuboidsResponse, error)
+	GetCuboid(context.Context, *GetRequest) (*Cuboid, error)
+	CreateCuboid(context.Context, *CreateCuboidRequest) (*Cuboid, error)
+	UpdateCuboid(context.Context, *UpdateCuboidRequest) (*Cuboid, error)
+	DeleteCuboid(context.Context, *DeleteCuboidRequest) (*DeleteResponse, error)
+	mustEmbedUnimplementedInventoryServer()
+}
+
+// UnimplementedInventoryServer must be embedded to have forward compatible implementations.
+type UnimplementedInventoryServer struct {
+}
+
+func (UnimplementedInventoryServer) ListBags(context.Context, *ListRequest) (*ListBagsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBags not implemented")
+}
+func (UnimplementedInventoryServer) GetBag(context.Context, *GetRequest) (*Bag, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBag not implemented")
+}
+func (UnimplementedInventoryServer) CreateBag(context.Context, *CreateBagRequest) (*Bag, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateBag not implemented")
+}
+func (UnimplementedInventoryServer) UpdateBag(context.Context, *UpdateBagRequest) (*Bag, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateBag not implemented")
+}
+func (UnimplementedInventoryServer) DeleteBag(context.Context, *DeleteBagRequest) (*DeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteBag not implemented")
+}
+func (UnimplementedInventoryServer) CheckFit(context.Context, *CheckFitRequest) (*CheckFitResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckFit not implemented")
+}
+func (UnimplementedInventoryServer) ListCuboids(context.Context, *ListRequest) (*ListCuboidsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCuboids not implemented")
+}
+func (UnimplementedInventoryServer) GetCuboid(context.Context, *GetRequest) (*Cuboid, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCuboid not implemented")
+}
+func (UnimplementedInventoryServer) CreateCuboid(context.Context, *CreateCuboidRequest) (*Cuboid, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateCuboid not implemented")
+}
+func (UnimplementedInventoryServer) UpdateCuboid(context.Context, *UpdateCuboidRequest) (*Cuboid, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateCuboid not implemented")
+}
+func (UnimplementedInventoryServer) DeleteCuboid(context.Context, *DeleteCuboidRequest) (*DeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteCuboid not implemented")
+}
+func (UnimplementedInventoryServer) mustEmbedUnimplementedInventoryServer() {}
+
+// UnsafeInventoryServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to InventoryServer will
+// result in compilation errors.
+type UnsafeInventoryServer interface {
+	mustEmbedUnimplementedInventoryServer()
+}
+
+func RegisterInventoryServer(s grpc.ServiceRegistrar, srv InventoryServer) {
+	s.RegisterService(&Inventory_ServiceDesc, srv)
+}
+
+func _Inventory_ListBags_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServer).ListBags(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Inventory_ListBags_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServer).ListBags(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Inventory_GetBag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServer).GetBag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Inventory_GetBag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServer).GetBag(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Inventory_CreateBag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateBagRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServer).CreateBag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Inventory_CreateBag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServer).CreateBag(ctx, req.(*CreateBagRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Inventory_UpdateBag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateBagRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServer).UpdateBag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Inventory_UpdateBag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServer).UpdateBag(ctx, req.(*UpdateBagRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Inventory_DeleteBag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteBagRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServer).DeleteBag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Inventory_DeleteBag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServer).DeleteBag(ctx, req.(*DeleteBagRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Inventory_CheckFit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckFitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServer).CheckFit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Inventory_CheckFit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServer).CheckFit(ctx, req.(*CheckFitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Inventory_ListCuboids_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServer).ListCuboids(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Inventory_ListCuboids_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServer).ListCuboids(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Inventory_GetCuboid_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServer).GetCuboid(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Inventory_GetCuboid_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServer).GetCuboid(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Inventory_CreateCuboid_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateCuboidRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServer).CreateCuboid(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Inventory_CreateCuboid_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServer).CreateCuboid(ctx, req.(*CreateCuboidRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Inventory_UpdateCuboid_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateCuboidRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServer).UpdateCuboid(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Inventory_UpdateCuboid_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServer).UpdateCuboid(ctx, req.(*UpdateCuboidRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Inventory_DeleteCuboid_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteCuboidRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServer).DeleteCuboid(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Inventory_DeleteCuboid_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServer).DeleteCuboid(ctx, req.(*DeleteCuboidRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Inventory_ServiceDesc is the grpc.ServiceDesc for Inventory service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Inventory_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "cuboid.v1.Inventory",
+	HandlerType: (*InventoryServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListBags",
+			Handler:    _Inventory_ListBags_Handler,
+		},
+		{
+			MethodName: "GetBag",
+			Handler:    _Inventory_GetBag_Handler,
+		},
+		{
+			MethodName: "CreateBag",
+			Handler:    _Inventory_CreateBag_Handler,
+		},
+		{
+			MethodName: "UpdateBag",
+			Handler:    _Inventory_UpdateBag_Handler,
+		},
+		{
+			MethodName: "DeleteBag",
+			Handler:    _Inventory_DeleteBag_Handler,
+		},
+		{
+			MethodName: "CheckFit",
+			Handler:    _Inventory_CheckFit_Handler,
+		},
+		{
+			MethodName: "ListCuboids",
+			Handler:    _Inventory_ListCuboids_Handler,
+		},
+		{
+			MethodName: "GetCuboid",
+			Handler:    _Inventory_GetCuboid_Handler,
+		},
+		{
+			MethodName: "CreateCuboid",
+			Handler:    _Inventory_CreateCuboid_Handler,
+		},
+		{
+			MethodName: "UpdateCuboid",
+			Handler:    _Inventory_UpdateCuboid_Handler,
+		},
+		{
+			MethodName: "DeleteCuboid",
+			Handler:    _Inventory_DeleteCuboid_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "inventory.proto",
+}
@@ -0,0 +1,47 @@
+// Package rpc serves the gRPC API, see pb/inventory.proto. The handlers read the records from the
+// store and change them through the inventory service, like the REST ones, and answer the same errors.
+package rpc
+
+import (
+	"cuboid-challenge/app/api"
+	"cuboid-challenge/app/inventory"
+	"cuboid-challenge/app/rpc/pb"
+	"cuboid-challenge/app/store"
+
+	"google.golang.org/grpc"
+)
+
+type server struct {
+	pb.UnimplementedInventoryServer
+
+	store     store.Store
+	inventory *inventory.Service
+}
+
+// NewServer returns the gRPC server of the API, its handlers read and write the records through the store.
+func NewServer(s store.Store, opts ...grpc.ServerOption) *grpc.Server {
+	srv := grpc.NewServer(opts...)
+	pb.RegisterInventoryServer(srv, &server{store: s, inventory: inventory.New(s)})
+
+	return srv
+}
+
+// expectVersion is the precondition of a request with an expected_version, none when it is zero.
+func expectVersion(expected uint64) inventory.Precondition {
+	if expected == 0 {
+		return nil
+	}
+
+	return func(version uint) bool { return uint64(version) == expected }
+}
+
+// listParams reads the parameters of a list request.
+func listParams(req *pb.ListRequest) api.ListParams {
+	params := api.ListParams{Cursor: req.Cursor, Sort: req.Sort, IncludeDeleted: req.IncludeDeleted, Filters: req.Filters}
+	if req.Limit != 0 {
+		limit := int(req.Limit)
+		params.Limit = &limit
+	}
+
+	return params
+}
@@ -0,0 +1,59 @@
+package rpc
+
+import (
+	"errors"
+	"net/http"
+
+	"cuboid-challenge/app/api"
+	"cuboid-challenge/app/models"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/runtime/protoiface"
+)
+
+// errorDomain is the domain of the ErrorInfo details, their reason is the problem code of the REST API.
+const errorDomain = "cuboid-challenge"
+
+// statusError reports err with the gRPC code matching the HTTP status the REST API answers it with.
+// The problem code is in an ErrorInfo detail, and the fields of a validation error in a BadRequest one.
+func statusError(err error) error {
+	t, detail := api.ProblemFor(err)
+
+	details := []protoiface.MessageV1{&errdetails.ErrorInfo{Reason: t.Code, Domain: errorDomain}}
+
+	var valErr models.ValidationErrors
+	if errors.As(err, &valErr) {
+		badRequest := &errdetails.BadRequest{}
+		for i := range valErr {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       models.JSONName(valErr[i].Field),
+				Description: valErr[i].Error(),
+			})
+		}
+		details = append(details, badRequest)
+	}
+
+	st := status.New(codeOf(t.Status), detail)
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		st = withDetails
+	}
+
+	return st.Err()
+}
+
+func codeOf(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.FailedPrecondition
+	case http.StatusPreconditionFailed:
+		return codes.Aborted
+	default:
+		return codes.Internal
+	}
+}
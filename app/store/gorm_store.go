@@ -0,0 +1,423 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"cuboid-challenge/app/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Gorm is the Store on a GORM connection.
+type Gorm struct {
+	db *gorm.DB
+}
+
+// NewGorm returns the Store on the connection.
+func NewGorm(db *gorm.DB) *Gorm {
+	return &Gorm{db}
+}
+
+func (s *Gorm) Bags() BagStore                       { return gormBags{s.db} }
+func (s *Gorm) Cuboids() CuboidStore                 { return gormCuboids{s.db} }
+func (s *Gorm) IdempotencyKeys() IdempotencyKeyStore { return gormIdempotencyKeys{s.db} }
+
+func (s *Gorm) Transaction(fn func(tx Store) error) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&Gorm{tx})
+	})
+}
+
+func (s *Gorm) Now() time.Time {
+	return s.db.NowFunc()
+}
+
+// notFound turns the GORM error for a missing record into ErrNotFound.
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+
+	return err
+}
+
+func scoped(db *gorm.DB, withDeleted bool) *gorm.DB {
+	if withDeleted {
+		return db.Unscoped()
+	}
+
+	return db
+}
+
+// preloadCuboids preloads the bag cuboids, including the deleted ones when asked.
+func preloadCuboids(db *gorm.DB, withDeleted bool) *gorm.DB {
+	if withDeleted {
+		return db.Preload("Cuboids", func(tx *gorm.DB) *gorm.DB { return tx.Unscoped() })
+	}
+
+	return db.Preload("Cuboids")
+}
+
+type gormBags struct {
+	db *gorm.DB
+}
+
+// bagPayloadSQL sums the volume of the cuboids in the bag, the deleted ones do not take room.
+const bagPayloadSQL = "COALESCE((SELECT SUM(cuboids.width * cuboids.height * cuboids.depth) FROM cuboids " +
+	"WHERE cuboids.bag_id = bags.id AND cuboids.deleted_at IS NULL), 0)"
+
+var bagList = gormList{
+	table: "bags",
+	sorts: map[string]string{
+		"id":              "bags.id",
+		"title":           "bags.title",
+		"volume":          "bags.volume",
+		"payloadVolume":   bagPayloadSQL,
+		"availableVolume": "(bags.volume - " + bagPayloadSQL + ")",
+	},
+	filters: map[string]string{
+		"disabled":           "bags.disabled = ?",
+		"minVolume":          "bags.volume >= ?",
+		"maxVolume":          "bags.volume <= ?",
+		"minAvailableVolume": "(bags.volume - " + bagPayloadSQL + ") >= ?",
+		"maxAvailableVolume": "(bags.volume - " + bagPayloadSQL + ") <= ?",
+	},
+}
+
+func (s gormBags) Get(id uint, withDeleted bool) (models.Bag, error) {
+	var bag models.Bag
+	if r := preloadCuboids(scoped(s.db, withDeleted), withDeleted).First(&bag, id); r.Error != nil {
+		return models.Bag{}, notFound(r.Error)
+	}
+
+	return bag, nil
+}
+
+func (s gormBags) Exists(id uint, withDeleted bool) (bool, error) {
+	var count int64
+	r := scoped(s.db, withDeleted).Model(&models.Bag{}).Where("id = ?", id).Count(&count)
+
+	return count > 0, r.Error
+}
+
+func (s gormBags) List(q ListQuery) ([]models.Bag, int64, *Cursor, error) {
+	var preload func(*gorm.DB) *gorm.DB
+	if !q.WithoutCuboids {
+		preload = func(tx *gorm.DB) *gorm.DB { return preloadCuboids(tx, q.IncludeDeleted) }
+	}
+
+	var bags []models.Bag
+	total, err := bagList.find(s.db, q, &bags, nil, preload)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	if len(bags) <= q.Limit {
+		return bags, total, nil, nil
+	}
+
+	bags = bags[:q.Limit]
+	next, err := bagList.cursor(s.db, q, bags[q.Limit-1].ID)
+
+	return bags, total, next, err
+}
+
+func (s gormBags) Enabled(ids []uint) ([]models.Bag, error) {
+	var bags []models.Bag
+	q := s.db.Preload("Cuboids").Where("disabled = ?", false).Order("id")
+	if len(ids) > 0 {
+		q = q.Where("id IN ?", ids)
+	}
+
+	if r := q.Find(&bags); r.Error != nil {
+		return nil, r.Error
+	}
+
+	return bags, nil
+}
+
+func (s gormBags) Count(ids []uint) (int64, error) {
+	var count int64
+	r := s.db.Model(&models.Bag{}).Where("id IN ?", ids).Count(&count)
+
+	return count, r.Error
+}
+
+// Lock locks the rows with a no-op update, which works on every supported database, unlike
+// SELECT ... FOR UPDATE.
+func (s gormBags) Lock(ids ...uint) error {
+	unique := map[uint]bool{}
+	sorted := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !unique[id] {
+			unique[id] = true
+			sorted = append(sorted, id)
+		}
+	}
+	// always lock in the same order to avoid deadlocks between two moves
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	for _, id := range sorted {
+		if r := s.db.Exec("UPDATE bags SET id = id WHERE id = ?", id); r.Error != nil {
+			return fmt.Errorf("failed to lock bag %d. %w", id, r.Error)
+		}
+	}
+
+	return nil
+}
+
+func (s gormBags) Create(bag *models.Bag) error {
+	return s.db.Create(bag).Error
+}
+
+// Update leaves the cuboids of the bag out, the preloaded ones may be stale.
+func (s gormBags) Update(bag *models.Bag, columns ...string) error {
+	return s.db.Unscoped().Model(bag).Select(columns).Omit(clause.Associations).Updates(bag).Error
+}
+
+func (s gormBags) Purge(before time.Time) (int64, error) {
+	// a bag still referenced by a cuboid, deleted more recently or not at all, has to stay
+	r := s.db.Unscoped().
+		Where("deleted_at < ?", before).
+		Where("NOT EXISTS (SELECT 1 FROM cuboids WHERE cuboids.bag_id = bags.id)").
+		Delete(&models.Bag{})
+
+	return r.RowsAffected, r.Error
+}
+
+type gormCuboids struct {
+	db *gorm.DB
+}
+
+const cuboidVolumeSQL = "(cuboids.width * cuboids.height * cuboids.depth)"
+
+var cuboidList = gormList{
+	table: "cuboids",
+	sorts: map[string]string{
+		"id":     "cuboids.id",
+		"width":  "cuboids.width",
+		"height": "cuboids.height",
+		"depth":  "cuboids.depth",
+		"volume": cuboidVolumeSQL,
+		"bagId":  "cuboids.bag_id",
+	},
+	filters: map[string]string{
+		"bagId":     "cuboids.bag_id = ?",
+		"disabled":  "cuboids.bag_id IN (SELECT bags.id FROM bags WHERE bags.disabled = ?)",
+		"minVolume": cuboidVolumeSQL + " >= ?",
+		"maxVolume": cuboidVolumeSQL + " <= ?",
+	},
+}
+
+func (s gormCuboids) Get(id uint, withDeleted bool) (models.Cuboid, error) {
+	var cuboid models.Cuboid
+	if r := scoped(s.db, withDeleted).First(&cuboid, id); r.Error != nil {
+		return models.Cuboid{}, notFound(r.Error)
+	}
+
+	return cuboid, nil
+}
+
+func (s gormCuboids) List(q ListQuery) ([]models.Cuboid, int64, *Cursor, error) {
+	var inBag func(*gorm.DB) *gorm.DB
+	if q.BagID != 0 {
+		inBag = func(tx *gorm.DB) *gorm.DB { return tx.Where("cuboids.bag_id = ?", q.BagID) }
+	}
+
+	var cuboids []models.Cuboid
+	total, err := cuboidList.find(s.db, q, &cuboids, inBag, nil)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	if len(cuboids) <= q.Limit {
+		return cuboids, total, nil, nil
+	}
+
+	cuboids = cuboids[:q.Limit]
+	next, err := cuboidList.cursor(s.db, q, cuboids[q.Limit-1].ID)
+
+	return cuboids, total, next, err
+}
+
+func (s gormCuboids) Existing(ids []uint) ([]uint, error) {
+	var found []uint
+	r := s.db.Model(&models.Cuboid{}).Where("id IN ?", ids).Pluck("id", &found)
+
+	return found, r.Error
+}
+
+func (s gormCuboids) InBags(bagIDs []uint, withDeleted bool) ([]models.Cuboid, error) {
+	cuboids := []models.Cuboid{}
+	if r := scoped(s.db, withDeleted).Where("bag_id IN ?", bagIDs).Order("id").Find(&cuboids); r.Error != nil {
+		return nil, r.Error
+	}
+
+	return cuboids, nil
+}
+
+func (s gormCuboids) Create(cuboid *models.Cuboid) error {
+	return s.db.Create(cuboid).Error
+}
+
+func (s gormCuboids) Update(cuboid *models.Cuboid, columns ...string) error {
+	return s.db.Unscoped().Model(cuboid).Select(columns).Updates(cuboid).Error
+}
+
+// Delete uses the version as a condition, a concurrent change makes it delete nothing.
+func (s gormCuboids) Delete(cuboid *models.Cuboid, version uint) (bool, error) {
+	r := s.db.Where("version = ?", version).Delete(cuboid)
+
+	return r.RowsAffected > 0, r.Error
+}
+
+func (s gormCuboids) DeleteMany(ids []uint) error {
+	return s.db.Where("id IN ?", ids).Delete(&models.Cuboid{}).Error
+}
+
+func (s gormCuboids) DeleteInBag(bagID uint, at time.Time) error {
+	return s.db.Session(&gorm.Session{SkipHooks: true}).Model(&models.Cuboid{}).
+		Where("bag_id = ? AND deleted_at IS NULL", bagID).Update("deleted_at", at).Error
+}
+
+func (s gormCuboids) RestoreInBag(bagID uint, deletedAt time.Time) error {
+	return s.db.Session(&gorm.Session{SkipHooks: true}).Unscoped().Model(&models.Cuboid{}).
+		Where("bag_id = ? AND deleted_at = ?", bagID, deletedAt).
+		Updates(map[string]interface{}{"deleted_at": nil, "version": gorm.Expr("version + 1")}).Error
+}
+
+func (s gormCuboids) Purge(before time.Time) (int64, error) {
+	r := s.db.Unscoped().Where("deleted_at < ?", before).Delete(&models.Cuboid{})
+
+	return r.RowsAffected, r.Error
+}
+
+type gormIdempotencyKeys struct {
+	db *gorm.DB
+}
+
+func (s gormIdempotencyKeys) Claim(record *models.IdempotencyKey) (bool, error) {
+	r := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+
+	return r.RowsAffected == 1, r.Error
+}
+
+func (s gormIdempotencyKeys) Get(key string) (models.IdempotencyKey, error) {
+	var stored models.IdempotencyKey
+	if r := s.db.Where(&models.IdempotencyKey{Key: key}).First(&stored); r.Error != nil {
+		return models.IdempotencyKey{}, notFound(r.Error)
+	}
+
+	return stored, nil
+}
+
+func (s gormIdempotencyKeys) Replace(createdAt time.Time, record *models.IdempotencyKey) (bool, error) {
+	r := s.db.Model(&models.IdempotencyKey{}).
+		Where(&models.IdempotencyKey{Key: record.Key, CreatedAt: createdAt}).
+		Updates(map[string]interface{}{
+			"request_hash": record.RequestHash, "status": 0, "e_tag": "", "content_type": "", "body": nil,
+			"created_at": record.CreatedAt,
+		})
+
+	return r.RowsAffected == 1, r.Error
+}
+
+func (s gormIdempotencyKeys) Finish(record *models.IdempotencyKey) error {
+	return s.db.Model(&models.IdempotencyKey{}).
+		Where(&models.IdempotencyKey{Key: record.Key, CreatedAt: record.CreatedAt}).
+		Updates(map[string]interface{}{
+			"status":       record.Status,
+			"e_tag":        record.ETag,
+			"content_type": record.ContentType,
+			"body":         record.Body,
+		}).Error
+}
+
+func (s gormIdempotencyKeys) Release(key string, createdAt time.Time) error {
+	return s.db.Where(&models.IdempotencyKey{Key: key, CreatedAt: createdAt}).Delete(&models.IdempotencyKey{}).Error
+}
+
+func (s gormIdempotencyKeys) Purge(before time.Time) (int64, error) {
+	r := s.db.Where("created_at < ?", before).Delete(&models.IdempotencyKey{})
+
+	return r.RowsAffected, r.Error
+}
+
+// gormList maps the sort keys and the filters of a list to SQL. The filters have a single placeholder for the value.
+type gormList struct {
+	table   string
+	sorts   map[string]string
+	filters map[string]string
+}
+
+// filtered applies the filters and the where condition the client does not choose, like the bag of a
+// nested route. It is the query the total count is taken from.
+func (l gormList) filtered(db *gorm.DB, q ListQuery, where func(*gorm.DB) *gorm.DB) *gorm.DB {
+	tx := scoped(db, q.IncludeDeleted)
+	if where != nil {
+		tx = where(tx)
+	}
+
+	for name, value := range q.Filters {
+		if cond, ok := l.filters[name]; ok {
+			tx = tx.Where(cond, value)
+		}
+	}
+
+	return tx
+}
+
+// find loads the page into dest, with the preloads, and returns the total count. It asks for one record
+// more than the limit, which tells whether there is a next page.
+func (l gormList) find(db *gorm.DB, q ListQuery, dest interface{}, where, preload func(*gorm.DB) *gorm.DB) (int64, error) {
+	expr, ok := l.sorts[q.Sort]
+	if !ok {
+		return 0, fmt.Errorf("unknown sort %q", q.Sort)
+	}
+
+	var total int64
+	if r := l.filtered(db, q, where).Model(dest).Count(&total); r.Error != nil {
+		return 0, r.Error
+	}
+
+	id := l.table + ".id"
+	cmp, dir := ">", "ASC"
+	if q.Desc {
+		cmp, dir = "<", "DESC"
+	}
+
+	tx := l.filtered(db, q, where)
+	if preload != nil {
+		tx = preload(tx)
+	}
+
+	if q.After != nil {
+		tx = tx.Where(
+			fmt.Sprintf("(%s %s ? OR (%s = ? AND %s %s ?))", expr, cmp, expr, id, cmp),
+			q.After.Value, q.After.Value, q.After.ID,
+		)
+	}
+
+	r := tx.Order(fmt.Sprintf("%s %s, %s %s", expr, dir, id, dir)).Limit(q.Limit + 1).Find(dest)
+
+	return total, r.Error
+}
+
+// cursor returns the cursor of the record lastID, the last of a page.
+func (l gormList) cursor(db *gorm.DB, q ListQuery, lastID uint) (*Cursor, error) {
+	var value interface{}
+	row := db.Table(l.table).Select(l.sorts[q.Sort]).Where(l.table+".id = ?", lastID).Row()
+	if err := row.Scan(&value); err != nil {
+		return nil, err
+	}
+
+	if b, ok := value.([]byte); ok {
+		value = string(b)
+	}
+
+	return &Cursor{Value: value, ID: lastID}, nil
+}
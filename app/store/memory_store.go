@@ -0,0 +1,790 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"cuboid-challenge/app/models"
+
+	"gorm.io/gorm"
+)
+
+// Memory is a Store that keeps the records in memory, each Memory store is isolated from the others.
+// Transactions run one at a time and roll back by restoring a copy of the records. The models are
+// validated on create and update, like the GORM hooks do.
+type Memory struct {
+	mu   *sync.Mutex
+	data *memoryData
+	// inTx tells the store is bound to a transaction, which already holds the lock
+	inTx bool
+}
+
+type memoryData struct {
+	bags     map[uint]models.Bag
+	cuboids  map[uint]models.Cuboid
+	keys     map[string]models.IdempotencyKey
+	lastID   uint
+	lastTime time.Time
+}
+
+// NewMemory returns an empty Memory store.
+func NewMemory() *Memory {
+	return &Memory{
+		mu: &sync.Mutex{},
+		data: &memoryData{
+			bags:    map[uint]models.Bag{},
+			cuboids: map[uint]models.Cuboid{},
+			keys:    map[string]models.IdempotencyKey{},
+		},
+	}
+}
+
+func (s *Memory) Bags() BagStore                       { return memoryBags{s} }
+func (s *Memory) Cuboids() CuboidStore                 { return memoryCuboids{s} }
+func (s *Memory) IdempotencyKeys() IdempotencyKeyStore { return memoryIdempotencyKeys{s} }
+
+func (s *Memory) Transaction(fn func(tx Store) error) error {
+	if s.inTx {
+		return fn(s)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saved := s.data.clone()
+	if err := fn(&Memory{mu: s.mu, data: s.data, inTx: true}); err != nil {
+		*s.data = saved
+
+		return err
+	}
+
+	return nil
+}
+
+// Now is in milliseconds like the GORM store, and always after the previous one, so two records
+// deleted one after the other are told apart.
+func (s *Memory) Now() time.Time {
+	unlock := s.lock()
+	defer unlock()
+
+	now := time.Now().Truncate(time.Millisecond)
+	if !now.After(s.data.lastTime) {
+		now = s.data.lastTime.Add(time.Millisecond)
+	}
+	s.data.lastTime = now
+
+	return now
+}
+
+// lock takes the store lock, unless the store is bound to a transaction, and returns the unlock function.
+func (s *Memory) lock() func() {
+	if s.inTx {
+		return func() {}
+	}
+
+	s.mu.Lock()
+
+	return s.mu.Unlock
+}
+
+func (d *memoryData) clone() memoryData {
+	c := *d
+	c.bags = make(map[uint]models.Bag, len(d.bags))
+	for id, bag := range d.bags {
+		c.bags[id] = bag
+	}
+	c.cuboids = make(map[uint]models.Cuboid, len(d.cuboids))
+	for id, cuboid := range d.cuboids {
+		c.cuboids[id] = cuboid
+	}
+	c.keys = make(map[string]models.IdempotencyKey, len(d.keys))
+	for key, record := range d.keys {
+		c.keys[key] = record
+	}
+
+	return c
+}
+
+func (d *memoryData) nextID() uint {
+	d.lastID++
+
+	return d.lastID
+}
+
+// bagCuboids returns the cuboids of the bag by id, the deleted ones only when asked.
+func (d *memoryData) bagCuboids(bagID uint, withDeleted bool) []models.Cuboid {
+	cuboids := []models.Cuboid{}
+	for _, cuboid := range d.cuboids {
+		if cuboid.BagID == bagID && (withDeleted || !cuboid.DeletedAt.Valid) {
+			cuboids = append(cuboids, cuboid)
+		}
+	}
+	sort.Slice(cuboids, func(i, j int) bool { return cuboids[i].ID < cuboids[j].ID })
+
+	return cuboids
+}
+
+func (d *memoryData) withCuboids(bag models.Bag, withDeleted bool) models.Bag {
+	bag.Cuboids = d.bagCuboids(bag.ID, withDeleted)
+
+	return bag
+}
+
+// payload is the volume the cuboids take in the bag, the deleted ones do not take room.
+func (d *memoryData) payload(bagID uint) int64 {
+	var payload int64
+	for _, cuboid := range d.bagCuboids(bagID, false) {
+		payload += int64(cuboid.Width * cuboid.Height * cuboid.Depth)
+	}
+
+	return payload
+}
+
+// copyColumns copies the named columns of src to dst, both pointers to the same model.
+func copyColumns(dst, src interface{}, columns []string) {
+	switch dst := dst.(type) {
+	case *models.Bag:
+		src := src.(*models.Bag)
+		for _, column := range columns {
+			switch column {
+			case "title":
+				dst.Title = src.Title
+			case "volume":
+				dst.Volume = src.Volume
+			case "width":
+				dst.Width = src.Width
+			case "height":
+				dst.Height = src.Height
+			case "depth":
+				dst.Depth = src.Depth
+			case "disabled":
+				dst.Disabled = src.Disabled
+			case "version":
+				dst.Version = src.Version
+			case "deleted_at":
+				dst.DeletedAt = src.DeletedAt
+			}
+		}
+	case *models.Cuboid:
+		src := src.(*models.Cuboid)
+		for _, column := range columns {
+			switch column {
+			case "width":
+				dst.Width = src.Width
+			case "height":
+				dst.Height = src.Height
+			case "depth":
+				dst.Depth = src.Depth
+			case "x":
+				dst.X = src.X
+			case "y":
+				dst.Y = src.Y
+			case "z":
+				dst.Z = src.Z
+			case "orientation":
+				dst.Orientation = src.Orientation
+			case "bag_id":
+				dst.BagID = src.BagID
+			case "version":
+				dst.Version = src.Version
+			case "deleted_at":
+				dst.DeletedAt = src.DeletedAt
+			}
+		}
+	}
+}
+
+type memoryBags struct {
+	s *Memory
+}
+
+func (b memoryBags) Get(id uint, withDeleted bool) (models.Bag, error) {
+	unlock := b.s.lock()
+	defer unlock()
+
+	bag, ok := b.s.data.bags[id]
+	if !ok || (bag.DeletedAt.Valid && !withDeleted) {
+		return models.Bag{}, ErrNotFound
+	}
+
+	return b.s.data.withCuboids(bag, withDeleted), nil
+}
+
+func (b memoryBags) Exists(id uint, withDeleted bool) (bool, error) {
+	unlock := b.s.lock()
+	defer unlock()
+
+	bag, ok := b.s.data.bags[id]
+
+	return ok && (withDeleted || !bag.DeletedAt.Valid), nil
+}
+
+func (b memoryBags) List(q ListQuery) ([]models.Bag, int64, *Cursor, error) {
+	unlock := b.s.lock()
+	defer unlock()
+
+	d := b.s.data
+	var rows []memoryRow
+	for _, bag := range d.bags {
+		if bag.DeletedAt.Valid && !q.IncludeDeleted {
+			continue
+		}
+
+		payload := d.payload(bag.ID)
+		available := int64(bag.Volume) - payload
+		values := map[string]interface{}{
+			"id":              int64(bag.ID),
+			"title":           bag.Title,
+			"volume":          int64(bag.Volume),
+			"payloadVolume":   payload,
+			"availableVolume": available,
+			"disabled":        bag.Disabled,
+		}
+		rows = append(rows, memoryRow{bag.ID, values})
+	}
+
+	page, total, next, err := bagMemoryList.page(rows, q)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	bags := make([]models.Bag, len(page))
+	for i, id := range page {
+		if bags[i] = d.bags[id]; !q.WithoutCuboids {
+			bags[i] = d.withCuboids(bags[i], q.IncludeDeleted)
+		}
+	}
+
+	return bags, total, next, nil
+}
+
+func (b memoryBags) Enabled(ids []uint) ([]models.Bag, error) {
+	unlock := b.s.lock()
+	defer unlock()
+
+	wanted := map[uint]bool{}
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	bags := []models.Bag{}
+	for _, bag := range b.s.data.bags {
+		if bag.DeletedAt.Valid || bag.Disabled || (len(ids) > 0 && !wanted[bag.ID]) {
+			continue
+		}
+		bags = append(bags, b.s.data.withCuboids(bag, false))
+	}
+	sort.Slice(bags, func(i, j int) bool { return bags[i].ID < bags[j].ID })
+
+	return bags, nil
+}
+
+func (b memoryBags) Count(ids []uint) (int64, error) {
+	unlock := b.s.lock()
+	defer unlock()
+
+	seen := map[uint]bool{}
+	var count int64
+	for _, id := range ids {
+		if bag, ok := b.s.data.bags[id]; ok && !bag.DeletedAt.Valid && !seen[id] {
+			count++
+		}
+		seen[id] = true
+	}
+
+	return count, nil
+}
+
+// Lock has nothing to do, a transaction holds the whole store.
+func (b memoryBags) Lock(ids ...uint) error {
+	return nil
+}
+
+func (b memoryBags) Create(bag *models.Bag) error {
+	if ok, err := models.Validate(*bag); !ok {
+		return err
+	}
+
+	unlock := b.s.lock()
+	defer unlock()
+
+	d := b.s.data
+	if err := bag.BeforeCreate(nil); err != nil {
+		return err
+	}
+	bag.ID = d.nextID()
+
+	stored := *bag
+	stored.Cuboids = nil
+	d.bags[bag.ID] = stored
+
+	for i := range bag.Cuboids {
+		cuboid := &bag.Cuboids[i]
+		cuboid.BagID = bag.ID
+		if err := cuboid.BeforeCreate(nil); err != nil {
+			return err
+		}
+		cuboid.ID = d.nextID()
+		d.cuboids[cuboid.ID] = *cuboid
+	}
+
+	return nil
+}
+
+func (b memoryBags) Update(bag *models.Bag, columns ...string) error {
+	if ok, err := models.Validate(*bag); !ok {
+		return err
+	}
+
+	unlock := b.s.lock()
+	defer unlock()
+
+	stored, ok := b.s.data.bags[bag.ID]
+	if !ok {
+		return nil
+	}
+	copyColumns(&stored, bag, columns)
+	b.s.data.bags[bag.ID] = stored
+
+	return nil
+}
+
+func (b memoryBags) Purge(before time.Time) (int64, error) {
+	unlock := b.s.lock()
+	defer unlock()
+
+	d := b.s.data
+	var purged int64
+	for id, bag := range d.bags {
+		if !bag.DeletedAt.Valid || !bag.DeletedAt.Time.Before(before) {
+			continue
+		}
+
+		if len(d.bagCuboids(id, true)) > 0 {
+			continue
+		}
+
+		delete(d.bags, id)
+		purged++
+	}
+
+	return purged, nil
+}
+
+type memoryCuboids struct {
+	s *Memory
+}
+
+func (c memoryCuboids) Get(id uint, withDeleted bool) (models.Cuboid, error) {
+	unlock := c.s.lock()
+	defer unlock()
+
+	cuboid, ok := c.s.data.cuboids[id]
+	if !ok || (cuboid.DeletedAt.Valid && !withDeleted) {
+		return models.Cuboid{}, ErrNotFound
+	}
+
+	return cuboid, nil
+}
+
+func (c memoryCuboids) List(q ListQuery) ([]models.Cuboid, int64, *Cursor, error) {
+	unlock := c.s.lock()
+	defer unlock()
+
+	d := c.s.data
+	var rows []memoryRow
+	for _, cuboid := range d.cuboids {
+		if (cuboid.DeletedAt.Valid && !q.IncludeDeleted) || (q.BagID != 0 && cuboid.BagID != q.BagID) {
+			continue
+		}
+
+		volume := int64(cuboid.Width * cuboid.Height * cuboid.Depth)
+		values := map[string]interface{}{
+			"id":       int64(cuboid.ID),
+			"width":    int64(cuboid.Width),
+			"height":   int64(cuboid.Height),
+			"depth":    int64(cuboid.Depth),
+			"volume":   volume,
+			"bagId":    int64(cuboid.BagID),
+			"disabled": d.bags[cuboid.BagID].Disabled,
+		}
+		rows = append(rows, memoryRow{cuboid.ID, values})
+	}
+
+	page, total, next, err := cuboidMemoryList.page(rows, q)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	cuboids := make([]models.Cuboid, len(page))
+	for i, id := range page {
+		cuboids[i] = d.cuboids[id]
+	}
+
+	return cuboids, total, next, nil
+}
+
+func (c memoryCuboids) Existing(ids []uint) ([]uint, error) {
+	unlock := c.s.lock()
+	defer unlock()
+
+	found := []uint{}
+	for _, id := range ids {
+		if cuboid, ok := c.s.data.cuboids[id]; ok && !cuboid.DeletedAt.Valid {
+			found = append(found, id)
+		}
+	}
+
+	return found, nil
+}
+
+func (c memoryCuboids) InBags(bagIDs []uint, withDeleted bool) ([]models.Cuboid, error) {
+	unlock := c.s.lock()
+	defer unlock()
+
+	inBags := make(map[uint]bool, len(bagIDs))
+	for _, id := range bagIDs {
+		inBags[id] = true
+	}
+
+	cuboids := []models.Cuboid{}
+	for _, cuboid := range c.s.data.cuboids {
+		if inBags[cuboid.BagID] && (withDeleted || !cuboid.DeletedAt.Valid) {
+			cuboids = append(cuboids, cuboid)
+		}
+	}
+	sort.Slice(cuboids, func(i, j int) bool { return cuboids[i].ID < cuboids[j].ID })
+
+	return cuboids, nil
+}
+
+func (c memoryCuboids) Create(cuboid *models.Cuboid) error {
+	if ok, err := models.Validate(*cuboid); !ok {
+		return err
+	}
+
+	unlock := c.s.lock()
+	defer unlock()
+
+	if err := cuboid.BeforeCreate(nil); err != nil {
+		return err
+	}
+	cuboid.ID = c.s.data.nextID()
+
+	stored := *cuboid
+	stored.Bag = nil
+	c.s.data.cuboids[cuboid.ID] = stored
+
+	return nil
+}
+
+func (c memoryCuboids) Update(cuboid *models.Cuboid, columns ...string) error {
+	if ok, err := models.Validate(*cuboid); !ok {
+		return err
+	}
+
+	unlock := c.s.lock()
+	defer unlock()
+
+	stored, ok := c.s.data.cuboids[cuboid.ID]
+	if !ok {
+		return nil
+	}
+	copyColumns(&stored, cuboid, columns)
+	c.s.data.cuboids[cuboid.ID] = stored
+
+	return nil
+}
+
+func (c memoryCuboids) Delete(cuboid *models.Cuboid, version uint) (bool, error) {
+	at := c.s.Now()
+
+	unlock := c.s.lock()
+	defer unlock()
+
+	stored, ok := c.s.data.cuboids[cuboid.ID]
+	if !ok || stored.DeletedAt.Valid || stored.Version != version {
+		return false, nil
+	}
+	stored.DeletedAt = gorm.DeletedAt{Time: at, Valid: true}
+	c.s.data.cuboids[cuboid.ID] = stored
+
+	return true, nil
+}
+
+func (c memoryCuboids) DeleteMany(ids []uint) error {
+	at := c.s.Now()
+
+	unlock := c.s.lock()
+	defer unlock()
+
+	for _, id := range ids {
+		if stored, ok := c.s.data.cuboids[id]; ok && !stored.DeletedAt.Valid {
+			stored.DeletedAt = gorm.DeletedAt{Time: at, Valid: true}
+			c.s.data.cuboids[id] = stored
+		}
+	}
+
+	return nil
+}
+
+func (c memoryCuboids) DeleteInBag(bagID uint, at time.Time) error {
+	unlock := c.s.lock()
+	defer unlock()
+
+	for _, cuboid := range c.s.data.bagCuboids(bagID, false) {
+		cuboid.DeletedAt = gorm.DeletedAt{Time: at, Valid: true}
+		c.s.data.cuboids[cuboid.ID] = cuboid
+	}
+
+	return nil
+}
+
+func (c memoryCuboids) RestoreInBag(bagID uint, deletedAt time.Time) error {
+	unlock := c.s.lock()
+	defer unlock()
+
+	for _, cuboid := range c.s.data.bagCuboids(bagID, true) {
+		if cuboid.DeletedAt.Valid && cuboid.DeletedAt.Time.Equal(deletedAt) {
+			cuboid.DeletedAt = gorm.DeletedAt{}
+			cuboid.Version++
+			c.s.data.cuboids[cuboid.ID] = cuboid
+		}
+	}
+
+	return nil
+}
+
+func (c memoryCuboids) Purge(before time.Time) (int64, error) {
+	unlock := c.s.lock()
+	defer unlock()
+
+	var purged int64
+	for id, cuboid := range c.s.data.cuboids {
+		if cuboid.DeletedAt.Valid && cuboid.DeletedAt.Time.Before(before) {
+			delete(c.s.data.cuboids, id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+type memoryIdempotencyKeys struct {
+	s *Memory
+}
+
+func (k memoryIdempotencyKeys) Claim(record *models.IdempotencyKey) (bool, error) {
+	unlock := k.s.lock()
+	defer unlock()
+
+	if _, ok := k.s.data.keys[record.Key]; ok {
+		return false, nil
+	}
+	k.s.data.keys[record.Key] = *record
+
+	return true, nil
+}
+
+func (k memoryIdempotencyKeys) Get(key string) (models.IdempotencyKey, error) {
+	unlock := k.s.lock()
+	defer unlock()
+
+	stored, ok := k.s.data.keys[key]
+	if !ok {
+		return models.IdempotencyKey{}, ErrNotFound
+	}
+
+	return stored, nil
+}
+
+func (k memoryIdempotencyKeys) Replace(createdAt time.Time, record *models.IdempotencyKey) (bool, error) {
+	unlock := k.s.lock()
+	defer unlock()
+
+	stored, ok := k.s.data.keys[record.Key]
+	if !ok || !stored.CreatedAt.Equal(createdAt) {
+		return false, nil
+	}
+	k.s.data.keys[record.Key] = models.IdempotencyKey{
+		Key: record.Key, RequestHash: record.RequestHash, CreatedAt: record.CreatedAt,
+	}
+
+	return true, nil
+}
+
+func (k memoryIdempotencyKeys) Finish(record *models.IdempotencyKey) error {
+	unlock := k.s.lock()
+	defer unlock()
+
+	stored, ok := k.s.data.keys[record.Key]
+	if !ok || !stored.CreatedAt.Equal(record.CreatedAt) {
+		return nil
+	}
+	stored.Status, stored.ETag, stored.ContentType, stored.Body = record.Status, record.ETag, record.ContentType, record.Body
+	k.s.data.keys[record.Key] = stored
+
+	return nil
+}
+
+func (k memoryIdempotencyKeys) Release(key string, createdAt time.Time) error {
+	unlock := k.s.lock()
+	defer unlock()
+
+	if stored, ok := k.s.data.keys[key]; ok && stored.CreatedAt.Equal(createdAt) {
+		delete(k.s.data.keys, key)
+	}
+
+	return nil
+}
+
+func (k memoryIdempotencyKeys) Purge(before time.Time) (int64, error) {
+	unlock := k.s.lock()
+	defer unlock()
+
+	var purged int64
+	for key, record := range k.s.data.keys {
+		if record.CreatedAt.Before(before) {
+			delete(k.s.data.keys, key)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+// memoryRow holds the values a list sorts and filters a record on, by name.
+type memoryRow struct {
+	id     uint
+	values map[string]interface{}
+}
+
+// memoryFilter compares the named value of a row with the filter value: "=", ">=" or "<=".
+type memoryFilter struct {
+	value string
+	op    string
+}
+
+// memoryList maps the filters of a list to the row values they compare, the sort keys are the value names.
+type memoryList struct {
+	filters map[string]memoryFilter
+}
+
+var bagMemoryList = memoryList{
+	filters: map[string]memoryFilter{
+		"disabled":           {"disabled", "="},
+		"minVolume":          {"volume", ">="},
+		"maxVolume":          {"volume", "<="},
+		"minAvailableVolume": {"availableVolume", ">="},
+		"maxAvailableVolume": {"availableVolume", "<="},
+	},
+}
+
+var cuboidMemoryList = memoryList{
+	filters: map[string]memoryFilter{
+		"bagId":     {"bagId", "="},
+		"disabled":  {"disabled", "="},
+		"minVolume": {"volume", ">="},
+		"maxVolume": {"volume", "<="},
+	},
+}
+
+// page filters and sorts the rows like gormList does, and returns the ids of the page, the total
+// count and the next cursor.
+func (l memoryList) page(rows []memoryRow, q ListQuery) ([]uint, int64, *Cursor, error) {
+	for _, row := range rows {
+		if _, ok := row.values[q.Sort]; !ok {
+			return nil, 0, nil, fmt.Errorf("unknown sort %q", q.Sort)
+		}
+		break
+	}
+
+	filtered := rows[:0]
+	for _, row := range rows {
+		if l.match(row, q.Filters) {
+			filtered = append(filtered, row)
+		}
+	}
+	total := int64(len(filtered))
+
+	// before reports whether a comes before b in the list order
+	before := func(a, b memoryRow) bool {
+		if c := compare(a.values[q.Sort], b.values[q.Sort]); c != 0 {
+			return (c < 0) != q.Desc
+		}
+
+		return a.id != b.id && (a.id < b.id) != q.Desc
+	}
+	sort.Slice(filtered, func(i, j int) bool { return before(filtered[i], filtered[j]) })
+
+	var ids []uint
+	var last memoryRow
+	for _, row := range filtered {
+		if q.After != nil && !before(memoryRow{q.After.ID, map[string]interface{}{q.Sort: q.After.Value}}, row) {
+			continue
+		}
+
+		if len(ids) == q.Limit {
+			return ids, total, &Cursor{Value: last.values[q.Sort], ID: last.id}, nil
+		}
+		ids = append(ids, row.id)
+		last = row
+	}
+
+	return ids, total, nil, nil
+}
+
+func (l memoryList) match(row memoryRow, filters map[string]interface{}) bool {
+	for name, value := range filters {
+		f, ok := l.filters[name]
+		if !ok {
+			continue
+		}
+
+		if v, ok := value.(uint64); ok {
+			value = int64(v)
+		}
+
+		c := compare(row.values[f.value], value)
+		switch {
+		case f.op == "=" && c != 0, f.op == ">=" && c < 0, f.op == "<=" && c > 0:
+			return false
+		}
+	}
+
+	return true
+}
+
+// compare orders two values of the same kind, the int64, string or bool values of the rows.
+func compare(a, b interface{}) int {
+	switch a := a.(type) {
+	case int64:
+		b, _ := b.(int64)
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+	case string:
+		b, _ := b.(string)
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+	case bool:
+		if b, _ := b.(bool); a != b {
+			if a {
+				return 1
+			}
+
+			return -1
+		}
+	}
+
+	return 0
+}
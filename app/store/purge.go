@@ -0,0 +1,35 @@
+package store
+
+import "time"
+
+// Purged counts the records a purge removed for good.
+type Purged struct {
+	Bags            int64
+	Cuboids         int64
+	IdempotencyKeys int64
+}
+
+// Purge permanently removes, in one transaction, the bags and cuboids deleted at least olderThan ago
+// and the idempotency keys past the replay window.
+func Purge(s Store, olderThan, window time.Duration) (Purged, error) {
+	var purged Purged
+	err := s.Transaction(func(tx Store) error {
+		before := tx.Now().Add(-olderThan)
+
+		var err error
+		if purged.Cuboids, err = tx.Cuboids().Purge(before); err != nil {
+			return err
+		}
+
+		if purged.Bags, err = tx.Bags().Purge(before); err != nil {
+			return err
+		}
+
+		// the keys past the replay window are of no use anymore
+		purged.IdempotencyKeys, err = tx.IdempotencyKeys().Purge(tx.Now().Add(-window))
+
+		return err
+	})
+
+	return purged, err
+}
@@ -0,0 +1,118 @@
+// Package store reads and writes the app records. Store is implemented on GORM for the app and in
+// memory for tests that need an isolated store of their own.
+package store
+
+import (
+	"errors"
+	"time"
+
+	"cuboid-challenge/app/models"
+)
+
+var ErrNotFound = errors.New("record not found")
+
+// Store gives access to the record stores. Inside Transaction they are bound to the transaction.
+type Store interface {
+	Bags() BagStore
+	Cuboids() CuboidStore
+	IdempotencyKeys() IdempotencyKeyStore
+
+	// Transaction runs fn in a transaction, which is committed when fn returns nil and rolled back otherwise.
+	Transaction(fn func(tx Store) error) error
+	// Now is the time the store records, see db.Connect.
+	Now() time.Time
+}
+
+// BagStore reads and writes bags. The reads leave out the soft deleted bags and cuboids unless asked otherwise.
+type BagStore interface {
+	// Get returns the bag with its cuboids, or ErrNotFound.
+	Get(id uint, withDeleted bool) (models.Bag, error)
+	// Exists tells whether the bag exists, without reading its cuboids.
+	Exists(id uint, withDeleted bool) (bool, error)
+	// List returns a page of bags with their cuboids, unless the query leaves them out, the total count
+	// of the bags that pass the filters, and the cursor of the next page, nil on the last one.
+	List(q ListQuery) ([]models.Bag, int64, *Cursor, error)
+	// Enabled returns the enabled bags with their cuboids, ordered by id. When ids is not empty only
+	// those bags are considered.
+	Enabled(ids []uint) ([]models.Bag, error)
+	// Count returns how many of the bags exist.
+	Count(ids []uint) (int64, error)
+
+	// Lock takes the write lock on the bags until the end of the transaction, so no other transaction
+	// can add to or take from them while their capacity is checked. It has to be the first statement
+	// of the transaction: MySQL takes the snapshot of a REPEATABLE READ transaction at its first read.
+	Lock(ids ...uint) error
+	// Create stores a new bag, along with its cuboids.
+	Create(bag *models.Bag) error
+	// Update writes the given columns of the bag, found by id whether it is deleted or not.
+	Update(bag *models.Bag, columns ...string) error
+	// Purge removes the bags deleted before the given time that no cuboid refers to anymore.
+	Purge(before time.Time) (int64, error)
+}
+
+// CuboidStore reads and writes cuboids. The reads leave out the soft deleted cuboids unless asked otherwise.
+type CuboidStore interface {
+	// Get returns the cuboid, or ErrNotFound.
+	Get(id uint, withDeleted bool) (models.Cuboid, error)
+	// List returns a page of cuboids, see BagStore.List.
+	List(q ListQuery) ([]models.Cuboid, int64, *Cursor, error)
+	// Existing returns which of the cuboids exist.
+	Existing(ids []uint) ([]uint, error)
+	// InBags returns the cuboids of the bags ordered by id, in a single read.
+	InBags(bagIDs []uint, withDeleted bool) ([]models.Cuboid, error)
+
+	// Create stores a new cuboid.
+	Create(cuboid *models.Cuboid) error
+	// Update writes the given columns of the cuboid, found by id whether it is deleted or not.
+	Update(cuboid *models.Cuboid, columns ...string) error
+	// Delete soft deletes the cuboid if it is still at the given version, and reports whether it did.
+	Delete(cuboid *models.Cuboid, version uint) (bool, error)
+	// DeleteMany soft deletes the cuboids.
+	DeleteMany(ids []uint) error
+	// DeleteInBag soft deletes the cuboids of the bag at the given time.
+	DeleteInBag(bagID uint, at time.Time) error
+	// RestoreInBag brings back the cuboids of the bag that were deleted at the given time, bumping their version.
+	RestoreInBag(bagID uint, deletedAt time.Time) error
+	// Purge removes the cuboids deleted before the given time.
+	Purge(before time.Time) (int64, error)
+}
+
+// IdempotencyKeyStore keeps the responses to the requests sent with an Idempotency-Key.
+type IdempotencyKeyStore interface {
+	// Claim stores the key unless it is already stored, and reports whether it did.
+	Claim(record *models.IdempotencyKey) (bool, error)
+	// Get returns the stored key, or ErrNotFound.
+	Get(key string) (models.IdempotencyKey, error)
+	// Replace takes over a key stored at createdAt, and reports whether it was still stored at that time.
+	Replace(createdAt time.Time, record *models.IdempotencyKey) (bool, error)
+	// Finish stores the status, the ETag, the content type and the body of the response to the request
+	// that claimed the key at record.CreatedAt.
+	Finish(record *models.IdempotencyKey) error
+	// Release removes the key claimed at createdAt.
+	Release(key string, createdAt time.Time) error
+	// Purge removes the keys created before the given time.
+	Purge(before time.Time) (int64, error)
+}
+
+// ListQuery is a page request. Sort and the filters are the names of the list endpoints, the store
+// knows what they stand for.
+type ListQuery struct {
+	Limit int
+	Sort  string
+	Desc  bool
+	// After is the cursor the page starts after.
+	After *Cursor
+	// Filters maps the filter names to their value, a uint64 or a bool.
+	Filters map[string]interface{}
+	// BagID limits cuboid lists to a bag when it is not zero.
+	BagID          uint
+	IncludeDeleted bool
+	// WithoutCuboids leaves the cuboids out of bag lists, for callers that load them on their own.
+	WithoutCuboids bool
+}
+
+// Cursor points at a record of a list: the value of the sort key and the id.
+type Cursor struct {
+	Value interface{}
+	ID    uint
+}
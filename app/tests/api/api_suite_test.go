@@ -0,0 +1,16 @@
+package api_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/reporters"
+	. "github.com/onsi/gomega"
+)
+
+func TestAPI(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	junitReporter := reporters.NewJUnitReporter("reports/api.xml")
+	RunSpecsWithDefaultAndCustomReporters(t, "API Suite", []Reporter{junitReporter})
+}
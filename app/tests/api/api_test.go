@@ -0,0 +1,66 @@
+package api_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"cuboid-challenge/app/api"
+	"cuboid-challenge/app/inventory"
+	"cuboid-challenge/app/store"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("API", func() {
+	Describe("ProblemFor", func() {
+		It("Finds the problem of a wrapped error", func() {
+			t, detail := api.ProblemFor(fmt.Errorf("%w, more", inventory.ErrBagNotEmpty))
+			Expect(t.Code).To(Equal("bag_not_empty"))
+			Expect(t.Status).To(Equal(http.StatusConflict))
+			Expect(detail).To(Equal("Bag is not empty, more"))
+		})
+
+		It("Answers an unknown error as internal", func() {
+			t, _ := api.ProblemFor(fmt.Errorf("boom"))
+			Expect(t.Code).To(Equal("internal"))
+		})
+	})
+
+	Describe("ListSpec", func() {
+		It("Defaults to the first page sorted by id", func() {
+			q, err := api.BagList.Parse(api.ListParams{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(q.Sort).To(Equal("id"))
+			Expect(q.Limit).To(Equal(50))
+		})
+
+		It("Parses the filters of the list and ignores the others", func() {
+			q, err := api.CuboidList.Parse(api.ListParams{Sort: "-volume", Filters: map[string]string{"bagId": "3", "title": "x"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(q.Desc).To(BeTrue())
+			Expect(q.Filters).To(Equal(map[string]interface{}{"bagId": uint64(3)}))
+		})
+
+		It("Rejects a limit out of range", func() {
+			limit := 0
+			_, err := api.BagList.Parse(api.ListParams{Limit: &limit})
+			Expect(err).To(MatchError(api.ErrInvalidLimit))
+		})
+
+		It("Takes back the cursor it issued for the same sort only", func() {
+			q, err := api.BagList.Parse(api.ListParams{Sort: "title"})
+			Expect(err).NotTo(HaveOccurred())
+
+			cursor, err := api.EncodeCursor(q, &store.Cursor{Value: "a", ID: 7})
+			Expect(err).NotTo(HaveOccurred())
+
+			next, err := api.BagList.Parse(api.ListParams{Sort: "title", Cursor: cursor})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(next.After).To(Equal(&store.Cursor{Value: "a", ID: 7}))
+
+			_, err = api.BagList.Parse(api.ListParams{Sort: "-title", Cursor: cursor})
+			Expect(err).To(MatchError(api.ErrInvalidCursor))
+		})
+	})
+})
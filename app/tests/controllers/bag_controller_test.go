@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"net/http/httptest"
 
+	. "cuboid-challenge/app/models"
+	"cuboid-challenge/app/store"
 	"cuboid-challenge/app/tests/factories"
 	"cuboid-challenge/app/tests/testutils"
 
@@ -14,11 +16,10 @@ import (
 
 var _ = Describe("Bag Controller", func() {
 	testutils.LoadEnv()
-	testutils.ConnectDB()
-	testutils.ClearDB()
+	var s store.Store
 
-	AfterEach(func() {
-		testutils.ClearDB()
+	BeforeEach(func() {
+		s = store.NewMemory()
 	})
 
 	var w *httptest.ResponseRecorder
@@ -26,9 +27,9 @@ var _ = Describe("Bag Controller", func() {
 	Describe("List", func() {
 		BeforeEach(func() {
 			for i := 0; i < 3; i++ {
-				testutils.AddRecords(factories.Bag())
+				testutils.AddRecords(s, factories.Bag())
 			}
-			w = testutils.MockRequest(http.MethodGet, "/bags", nil)
+			w = testutils.MockRequest(s, http.MethodGet, "/bags", nil)
 		})
 
 		It("Response HTTP status code 200", func() {
@@ -51,11 +52,11 @@ var _ = Describe("Bag Controller", func() {
 		bag := factories.Bag()
 
 		BeforeEach(func() {
-			testutils.AddRecords(&bag)
+			testutils.AddRecords(s, bag)
 		})
 
 		JustBeforeEach(func() {
-			w = testutils.MockRequest(http.MethodGet, "/bags/"+fmt.Sprintf("%v", bagID), nil)
+			w = testutils.MockRequest(s, http.MethodGet, "/bags/"+fmt.Sprintf("%v", bagID), nil)
 		})
 
 		Context("When the bag is present", func() {
@@ -92,7 +93,7 @@ var _ = Describe("Bag Controller", func() {
 
 		JustBeforeEach(func() {
 			body, _ := testutils.SerializeToString(bagPayload)
-			w = testutils.MockRequest(http.MethodPost, "/bags", &body)
+			w = testutils.MockRequest(s, http.MethodPost, "/bags", &body)
 		})
 
 		Context("When the payload is valid", func() {
@@ -125,4 +126,188 @@ var _ = Describe("Bag Controller", func() {
 			})
 		})
 	})
+
+	Describe("Update", func() {
+		var bag *Bag
+		var bagID uint
+		bagPayload := map[string]interface{}{}
+
+		BeforeEach(func() {
+			bag = &Bag{
+				Title:   "A bag",
+				Volume:  10,
+				Cuboids: []Cuboid{{Width: 2, Height: 2, Depth: 2}},
+			}
+			testutils.AddRecords(s, bag)
+			bagID = bag.ID
+			bagPayload = map[string]interface{}{}
+		})
+
+		JustBeforeEach(func() {
+			body, _ := testutils.SerializeToString(bagPayload)
+			w = testutils.MockRequest(s, http.MethodPatch, fmt.Sprintf("/bags/%v", bagID), &body)
+		})
+
+		Context("When the bag is disabled", func() {
+			BeforeEach(func() {
+				bagPayload["disabled"] = true
+			})
+
+			It("Response HTTP status code 200", func() {
+				Expect(w.Code).To(Equal(200))
+			})
+
+			It("Returns the disabled bag and keeps the other fields", func() {
+				m, _ := testutils.Deserialize(w.Body.String())
+				Expect(m["disabled"]).To(BeTrue())
+				Expect(m["title"]).To(Equal("A bag"))
+				Expect(m["volume"]).To(BeEquivalentTo(10))
+				Expect(m["version"]).To(BeEquivalentTo(2))
+			})
+		})
+
+		Context("When the title and volume change", func() {
+			BeforeEach(func() {
+				bagPayload["title"] = "Renamed"
+				bagPayload["volume"] = 8
+			})
+
+			It("Stores the changes", func() {
+				Expect(w.Code).To(Equal(200))
+
+				var stored Bag
+				testutils.FindRecord(s, &stored, bag.ID)
+				Expect(stored.Title).To(Equal("Renamed"))
+				Expect(stored.Volume).To(BeEquivalentTo(8))
+
+				Expect(testutils.CountCuboids(s, 0)).To(BeEquivalentTo(1))
+			})
+		})
+
+		Context("When the volume is below the payload", func() {
+			BeforeEach(func() {
+				bagPayload["volume"] = 7
+			})
+
+			It("Does not shrink the bag", func() {
+				Expect(w.Code).To(Equal(400))
+				m, _ := testutils.Deserialize(w.Body.String())
+				Expect(m["error"]).To(Equal("Volume is smaller than the bag payload"))
+			})
+		})
+
+		Context("When the title is blank", func() {
+			BeforeEach(func() {
+				bagPayload["title"] = ""
+			})
+
+			It("Response HTTP status code 400", func() {
+				Expect(w.Code).To(Equal(400))
+			})
+		})
+
+		Context("When the bag is not present", func() {
+			BeforeEach(func() {
+				bagID = 99999
+			})
+
+			It("Response HTTP status code 404", func() {
+				Expect(w.Code).To(Equal(404))
+			})
+		})
+	})
+
+	Describe("Delete", func() {
+		var bag, target *Bag
+		var query string
+
+		BeforeEach(func() {
+			bag = &Bag{
+				Title:   "A bag",
+				Volume:  10,
+				Cuboids: []Cuboid{{Width: 1, Height: 1, Depth: 1}, {Width: 1, Height: 1, Depth: 2}},
+			}
+			target = &Bag{Title: "Target", Volume: 5}
+			testutils.AddRecords(s, bag, target)
+			query = ""
+		})
+
+		JustBeforeEach(func() {
+			w = testutils.MockRequest(s, http.MethodDelete, fmt.Sprintf("/bags/%v%s", bag.ID, query), nil)
+		})
+
+		Context("When the bag has cuboids and no policy is given", func() {
+			It("Refuses to delete the bag", func() {
+				Expect(w.Code).To(Equal(409))
+				Expect(testutils.FindRecord(s, &Bag{}, bag.ID)).To(BeTrue())
+			})
+		})
+
+		Context("When the bag is empty", func() {
+			BeforeEach(func() {
+				Expect(s.Cuboids().DeleteInBag(bag.ID, s.Now())).To(Succeed())
+			})
+
+			It("Deletes the bag", func() {
+				Expect(w.Code).To(Equal(200))
+				Expect(testutils.FindRecord(s, &Bag{}, bag.ID)).To(BeFalse())
+			})
+		})
+
+		Context("When the cuboids are cascaded", func() {
+			BeforeEach(func() {
+				query = "?cuboids=cascade"
+			})
+
+			It("Deletes the bag and its cuboids", func() {
+				Expect(w.Code).To(Equal(200))
+				Expect(testutils.FindRecord(s, &Bag{}, bag.ID)).To(BeFalse())
+				Expect(testutils.CountCuboids(s, bag.ID)).To(BeZero())
+			})
+		})
+
+		Context("When the cuboids are moved", func() {
+			BeforeEach(func() {
+				query = fmt.Sprintf("?cuboids=move&targetBagId=%v", target.ID)
+			})
+
+			It("Moves the cuboids to the target bag", func() {
+				Expect(w.Code).To(Equal(200))
+				Expect(testutils.FindRecord(s, &Bag{}, bag.ID)).To(BeFalse())
+				Expect(testutils.CountCuboids(s, target.ID)).To(BeEquivalentTo(2))
+			})
+
+			Context("When the target bag has no room", func() {
+				BeforeEach(func() {
+					target.Volume = 2
+					testutils.UpdateRecord(s, target, "volume")
+				})
+
+				It("Keeps the bag and its cuboids", func() {
+					Expect(w.Code).To(Equal(400))
+					Expect(testutils.CountCuboids(s, bag.ID)).To(BeEquivalentTo(2))
+				})
+			})
+
+			Context("When the target bag is not present", func() {
+				BeforeEach(func() {
+					query = "?cuboids=move&targetBagId=99999"
+				})
+
+				It("Response HTTP status code 404", func() {
+					Expect(w.Code).To(Equal(404))
+				})
+			})
+		})
+
+		Context("When the policy is unknown", func() {
+			BeforeEach(func() {
+				query = "?cuboids=orphan"
+			})
+
+			It("Response HTTP status code 400", func() {
+				Expect(w.Code).To(Equal(400))
+			})
+		})
+	})
 })
@@ -0,0 +1,131 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "cuboid-challenge/app/models"
+	"cuboid-challenge/app/store"
+	"cuboid-challenge/app/tests/testutils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Bag cuboids", func() {
+	testutils.LoadEnv()
+	var s store.Store
+
+	BeforeEach(func() {
+		s = store.NewMemory()
+	})
+
+	var w *httptest.ResponseRecorder
+	var bag, other *Bag
+
+	BeforeEach(func() {
+		bag = &Bag{Title: "A bag", Volume: 10, Cuboids: []Cuboid{{Width: 1, Height: 1, Depth: 1}, {Width: 1, Height: 1, Depth: 2}}}
+		other = &Bag{Title: "Other", Volume: 10, Cuboids: []Cuboid{{Width: 2, Height: 2, Depth: 2}}}
+		testutils.AddRecords(s, bag, other)
+	})
+
+	path := func(b *Bag, suffix string) string {
+		return fmt.Sprintf("/bags/%v/cuboids%s", b.ID, suffix)
+	}
+
+	Describe("List", func() {
+		It("Returns only the cuboids of the bag", func() {
+			w = testutils.MockRequest(s, http.MethodGet, path(bag, ""), nil)
+			Expect(w.Code).To(Equal(200))
+
+			l, _ := testutils.DeserializeList(w.Body.String())
+			Expect(l).To(HaveLen(2))
+			for _, m := range l {
+				Expect(m["bagId"]).To(BeEquivalentTo(bag.ID))
+			}
+			Expect(w.Header().Get("X-Total-Count")).To(Equal("2"))
+		})
+
+		It("Response HTTP status code 404 when the bag is not present", func() {
+			w = testutils.MockRequest(s, http.MethodGet, "/bags/99999/cuboids", nil)
+			Expect(w.Code).To(Equal(404))
+		})
+	})
+
+	Describe("Get", func() {
+		It("Returns a cuboid of the bag", func() {
+			w = testutils.MockRequest(s, http.MethodGet, path(bag, fmt.Sprintf("/%v", bag.Cuboids[0].ID)), nil)
+			Expect(w.Code).To(Equal(200))
+		})
+
+		It("Response HTTP status code 404 when the cuboid is in another bag", func() {
+			w = testutils.MockRequest(s, http.MethodGet, path(bag, fmt.Sprintf("/%v", other.Cuboids[0].ID)), nil)
+			Expect(w.Code).To(Equal(404))
+		})
+	})
+
+	Describe("Create", func() {
+		It("Creates the cuboid in the bag of the path", func() {
+			body := fmt.Sprintf(`{"width": 1, "height": 1, "depth": 1, "bagId": %v}`, other.ID)
+			w = testutils.MockRequest(s, http.MethodPost, path(bag, ""), &body)
+			Expect(w.Code).To(Equal(201))
+
+			m, _ := testutils.Deserialize(w.Body.String())
+			Expect(m["bagId"]).To(BeEquivalentTo(bag.ID))
+		})
+
+		It("Checks the capacity of the bag", func() {
+			body := `{"width": 2, "height": 2, "depth": 2}`
+			w = testutils.MockRequest(s, http.MethodPost, path(bag, ""), &body)
+			Expect(w.Code).To(Equal(400))
+		})
+	})
+
+	Describe("Update", func() {
+		It("Updates a cuboid of the bag", func() {
+			body := `{"width": 2, "height": 1, "depth": 1}`
+			w = testutils.MockRequest(s, http.MethodPut, path(bag, fmt.Sprintf("/%v", bag.Cuboids[0].ID)), &body)
+			Expect(w.Code).To(Equal(200))
+
+			m, _ := testutils.Deserialize(w.Body.String())
+			Expect(m["bagId"]).To(BeEquivalentTo(bag.ID))
+		})
+
+		It("Keeps the cuboid in the bag of the path", func() {
+			body := fmt.Sprintf(`{"width": 1, "height": 1, "depth": 1, "bagId": %v}`, other.ID)
+			w = testutils.MockRequest(s, http.MethodPut, path(bag, fmt.Sprintf("/%v", bag.Cuboids[0].ID)), &body)
+			Expect(w.Code).To(Equal(200))
+
+			m, _ := testutils.Deserialize(w.Body.String())
+			Expect(m["bagId"]).To(BeEquivalentTo(bag.ID))
+
+			patch := fmt.Sprintf(`{"bagId": %v}`, other.ID)
+			w = testutils.MockRequest(s, http.MethodPatch, path(bag, fmt.Sprintf("/%v", bag.Cuboids[1].ID)), &patch)
+			Expect(w.Code).To(Equal(200))
+
+			m, _ = testutils.Deserialize(w.Body.String())
+			Expect(m["bagId"]).To(BeEquivalentTo(bag.ID))
+		})
+
+		It("Response HTTP status code 404 when the cuboid is in another bag", func() {
+			body := `{"width": 1, "height": 1, "depth": 1}`
+			w = testutils.MockRequest(s, http.MethodPut, path(bag, fmt.Sprintf("/%v", other.Cuboids[0].ID)), &body)
+			Expect(w.Code).To(Equal(404))
+		})
+	})
+
+	Describe("Delete", func() {
+		It("Deletes a cuboid of the bag", func() {
+			w = testutils.MockRequest(s, http.MethodDelete, path(bag, fmt.Sprintf("/%v", bag.Cuboids[0].ID)), nil)
+			Expect(w.Code).To(Equal(200))
+			Expect(testutils.FindRecord(s, &Cuboid{}, bag.Cuboids[0].ID)).To(BeFalse())
+		})
+
+		It("Response HTTP status code 404 when the cuboid is in another bag", func() {
+			w = testutils.MockRequest(s, http.MethodDelete, path(bag, fmt.Sprintf("/%v", other.Cuboids[0].ID)), nil)
+			Expect(w.Code).To(Equal(404))
+			Expect(testutils.FindRecord(s, &Cuboid{}, other.Cuboids[0].ID)).To(BeTrue())
+		})
+	})
+})
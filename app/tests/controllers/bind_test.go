@@ -0,0 +1,159 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"cuboid-challenge/app/config"
+	. "cuboid-challenge/app/models"
+	"cuboid-challenge/app/store"
+	"cuboid-challenge/app/tests/testutils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Request bodies", func() {
+	testutils.LoadEnv()
+	var s store.Store
+
+	BeforeEach(func() {
+		s = store.NewMemory()
+	})
+
+	var w *httptest.ResponseRecorder
+	var bag *Bag
+	var body string
+	var headers map[string]string
+
+	BeforeEach(func() {
+		bag = &Bag{Title: "A bag", Volume: 10, Cuboids: []Cuboid{{Width: 1, Height: 1, Depth: 1}}}
+		testutils.AddRecords(s, bag)
+		headers = map[string]string{}
+	})
+
+	problem := func() map[string]interface{} {
+		Expect(w.Code).To(Equal(400))
+		m, _ := testutils.Deserialize(w.Body.String())
+
+		return m
+	}
+
+	Describe("Create cuboid", func() {
+		JustBeforeEach(func() {
+			w = testutils.MockRequestWithHeaders(s, http.MethodPost, "/cuboids", &body, headers)
+		})
+
+		Context("When a size is negative", func() {
+			BeforeEach(func() {
+				body = fmt.Sprintf(`{"width": -1, "height": 1, "depth": 1, "bagId": %v}`, bag.ID)
+			})
+
+			It("Names the field", func() {
+				m := problem()
+				Expect(m["code"]).To(Equal("invalid_body"))
+				Expect(m["field"]).To(Equal("width"))
+				Expect(m["detail"]).To(Equal("width must be a non-negative number"))
+			})
+
+			It("Translates the message", func() {
+				headers["Accept-Language"] = "es"
+				w = testutils.MockRequestWithHeaders(s, http.MethodPost, "/cuboids", &body, headers)
+				Expect(problem()["detail"]).To(Equal("width debe ser un número no negativo"))
+			})
+		})
+
+		Context("When a number is sent as a string", func() {
+			BeforeEach(func() {
+				body = fmt.Sprintf(`{"width": 1, "height": "1", "depth": 1, "bagId": %v}`, bag.ID)
+			})
+
+			It("Names the field", func() {
+				Expect(problem()["field"]).To(Equal("height"))
+			})
+		})
+
+		Context("When the JSON is malformed", func() {
+			BeforeEach(func() {
+				body = `{"width": 1,`
+			})
+
+			It("Responds with an invalid body problem", func() {
+				Expect(problem()["code"]).To(Equal("invalid_body"))
+			})
+		})
+
+		Context("When the body is empty", func() {
+			BeforeEach(func() {
+				body = ""
+			})
+
+			It("Responds with an invalid body problem", func() {
+				Expect(problem()["detail"]).To(Equal("The request body is empty"))
+			})
+		})
+
+		Context("When the body is larger than the limit", func() {
+			BeforeEach(func() {
+				body = fmt.Sprintf(`{"width": 1, "height": 1, "depth": 1, "bagId": %v, "pad": "%s"}`,
+					bag.ID, strings.Repeat("x", int(config.ENV.MaxBodyBytes)))
+			})
+
+			It("Responds with a body too large problem", func() {
+				Expect(problem()["code"]).To(Equal("body_too_large"))
+			})
+		})
+
+		Context("When a field is unknown", func() {
+			BeforeEach(func() {
+				body = fmt.Sprintf(`{"width": 1, "height": 1, "depth": 1, "bagId": %v, "colour": "red"}`, bag.ID)
+			})
+
+			It("Ignores it by default", func() {
+				Expect(w.Code).To(Equal(201))
+			})
+
+			Context("When unknown fields are rejected", func() {
+				BeforeEach(func() {
+					config.ENV.RejectUnknownFields = true
+				})
+
+				AfterEach(func() {
+					config.ENV.RejectUnknownFields = false
+				})
+
+				It("Names the field", func() {
+					m := problem()
+					Expect(m["field"]).To(Equal("colour"))
+					Expect(m["detail"]).To(Equal("colour is not a known field"))
+				})
+			})
+		})
+	})
+
+	Describe("Update cuboid", func() {
+		BeforeEach(func() {
+			body = `{"width": 1, "height": 1, "depth": true}`
+			w = testutils.MockRequest(s, http.MethodPut, fmt.Sprintf("/cuboids/%v", bag.Cuboids[0].ID), &body)
+		})
+
+		It("Names the field", func() {
+			Expect(problem()["field"]).To(Equal("depth"))
+		})
+	})
+
+	Describe("Create bag", func() {
+		BeforeEach(func() {
+			body = `{"title": 7, "volume": 1}`
+			w = testutils.MockRequest(s, http.MethodPost, "/bags", &body)
+		})
+
+		It("Names the field", func() {
+			m := problem()
+			Expect(m["field"]).To(Equal("title"))
+			Expect(m["detail"]).To(Equal("title must be a string"))
+		})
+	})
+})
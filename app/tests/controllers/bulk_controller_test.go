@@ -0,0 +1,133 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "cuboid-challenge/app/models"
+	"cuboid-challenge/app/store"
+	"cuboid-challenge/app/tests/testutils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Bulk Controller", func() {
+	testutils.LoadEnv()
+	var s store.Store
+
+	BeforeEach(func() {
+		s = store.NewMemory()
+	})
+
+	var w *httptest.ResponseRecorder
+	var bag, other *Bag
+
+	BeforeEach(func() {
+		bag = &Bag{Title: "A bag", Volume: 10, Cuboids: []Cuboid{{Width: 1, Height: 1, Depth: 2}}}
+		other = &Bag{Title: "Other", Volume: 8}
+		testutils.AddRecords(s, bag, other)
+	})
+
+	results := func() []interface{} {
+		m, _ := testutils.Deserialize(w.Body.String())
+
+		return m["results"].([]interface{})
+	}
+
+	Describe("Create", func() {
+		var body string
+
+		JustBeforeEach(func() {
+			w = testutils.MockRequest(s, http.MethodPost, "/cuboids/bulk", &body)
+		})
+
+		Context("When every cuboid fits", func() {
+			BeforeEach(func() {
+				body = fmt.Sprintf(`[
+					{"width": 2, "height": 2, "depth": 2, "bagId": %v},
+					{"width": 2, "height": 2, "depth": 2, "bagId": %v}
+				]`, bag.ID, other.ID)
+			})
+
+			It("Creates them all", func() {
+				Expect(w.Code).To(Equal(201))
+				Expect(results()).To(HaveLen(2))
+				Expect(testutils.CountCuboids(s, 0)).To(BeEquivalentTo(3))
+			})
+		})
+
+		Context("When the cuboids for a bag exceed its available volume together", func() {
+			BeforeEach(func() {
+				body = fmt.Sprintf(`[
+					{"width": 1, "height": 1, "depth": 1, "bagId": %v},
+					{"width": 2, "height": 2, "depth": 2, "bagId": %v},
+					{"width": 1, "height": 1, "depth": 1, "bagId": %v}
+				]`, other.ID, bag.ID, bag.ID)
+			})
+
+			It("Creates none and reports the failing item", func() {
+				Expect(w.Code).To(Equal(400))
+				Expect(testutils.CountCuboids(s, 0)).To(BeEquivalentTo(1))
+
+				r := results()
+				Expect(r[0].(map[string]interface{})["error"]).To(BeNil())
+				Expect(r[1].(map[string]interface{})["error"]).To(BeNil())
+				Expect(r[2].(map[string]interface{})["error"]).To(Equal("Insufficient capacity in bag"))
+			})
+		})
+
+		Context("When a bag is not present", func() {
+			BeforeEach(func() {
+				body = `[{"width": 1, "height": 1, "depth": 1, "bagId": 99999}]`
+			})
+
+			It("Reports the item as not found", func() {
+				Expect(w.Code).To(Equal(400))
+				Expect(results()[0].(map[string]interface{})["status"]).To(BeEquivalentTo(404))
+			})
+		})
+
+		Context("When the list is empty", func() {
+			BeforeEach(func() {
+				body = `[]`
+			})
+
+			It("Response HTTP status code 400", func() {
+				Expect(w.Code).To(Equal(400))
+			})
+		})
+	})
+
+	Describe("Delete", func() {
+		var body string
+
+		JustBeforeEach(func() {
+			w = testutils.MockRequest(s, http.MethodDelete, "/cuboids/bulk", &body)
+		})
+
+		Context("When every cuboid is present", func() {
+			BeforeEach(func() {
+				body = fmt.Sprintf(`[%v]`, bag.Cuboids[0].ID)
+			})
+
+			It("Deletes them", func() {
+				Expect(w.Code).To(Equal(200))
+				Expect(testutils.CountCuboids(s, 0)).To(BeZero())
+			})
+		})
+
+		Context("When a cuboid is not present", func() {
+			BeforeEach(func() {
+				body = fmt.Sprintf(`[%v, 99999]`, bag.Cuboids[0].ID)
+			})
+
+			It("Deletes none", func() {
+				Expect(w.Code).To(Equal(400))
+				Expect(testutils.CountCuboids(s, 0)).To(BeEquivalentTo(1))
+				Expect(results()[1].(map[string]interface{})["status"]).To(BeEquivalentTo(404))
+			})
+		})
+	})
+})
@@ -0,0 +1,116 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "cuboid-challenge/app/models"
+	"cuboid-challenge/app/store"
+	"cuboid-challenge/app/tests/testutils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Optimistic concurrency", func() {
+	testutils.LoadEnv()
+	var s store.Store
+
+	BeforeEach(func() {
+		s = store.NewMemory()
+	})
+
+	var w *httptest.ResponseRecorder
+	var bag *Bag
+	var headers map[string]string
+
+	BeforeEach(func() {
+		bag = &Bag{Title: "A bag", Volume: 10, Cuboids: []Cuboid{{Width: 1, Height: 1, Depth: 1}}}
+		testutils.AddRecords(s, bag)
+		headers = map[string]string{}
+	})
+
+	Describe("Get", func() {
+		It("Returns the bag version as ETag", func() {
+			w = testutils.MockRequest(s, http.MethodGet, fmt.Sprintf("/bags/%v", bag.ID), nil)
+			Expect(w.Header().Get("ETag")).To(Equal(`"1"`))
+		})
+
+		It("Returns the cuboid version as ETag", func() {
+			w = testutils.MockRequest(s, http.MethodGet, fmt.Sprintf("/cuboids/%v", bag.Cuboids[0].ID), nil)
+			Expect(w.Header().Get("ETag")).To(Equal(`"1"`))
+		})
+	})
+
+	Describe("Update cuboid", func() {
+		JustBeforeEach(func() {
+			body := `{"width": 2, "height": 1, "depth": 1}`
+			path := fmt.Sprintf("/cuboids/%v", bag.Cuboids[0].ID)
+			w = testutils.MockRequestWithHeaders(s, http.MethodPut, path, &body, headers)
+		})
+
+		Context("When If-Match has the stored version", func() {
+			BeforeEach(func() {
+				headers["If-Match"] = `"1"`
+			})
+
+			It("Updates the cuboid and bumps the version", func() {
+				Expect(w.Code).To(Equal(200))
+				Expect(w.Header().Get("ETag")).To(Equal(`"2"`))
+			})
+		})
+
+		Context("When If-Match has the stored version as a weak tag", func() {
+			BeforeEach(func() {
+				headers["If-Match"] = `W/"1"`
+			})
+
+			It("Response HTTP status code 412", func() {
+				Expect(w.Code).To(Equal(412))
+			})
+		})
+
+		Context("When If-Match has an older version", func() {
+			BeforeEach(func() {
+				bag.Cuboids[0].Version = 2
+				testutils.UpdateRecord(s, &bag.Cuboids[0], "version")
+				headers["If-Match"] = `"1"`
+			})
+
+			It("Response HTTP status code 412", func() {
+				Expect(w.Code).To(Equal(412))
+
+				var cuboid Cuboid
+				testutils.FindRecord(s, &cuboid, bag.Cuboids[0].ID)
+				Expect(cuboid.Width).To(BeEquivalentTo(1))
+			})
+		})
+	})
+
+	Describe("Delete", func() {
+		BeforeEach(func() {
+			headers["If-Match"] = `"7"`
+		})
+
+		It("Refuses to delete a cuboid at another version", func() {
+			path := fmt.Sprintf("/cuboids/%v", bag.Cuboids[0].ID)
+			w = testutils.MockRequestWithHeaders(s, http.MethodDelete, path, nil, headers)
+			Expect(w.Code).To(Equal(412))
+			Expect(testutils.FindRecord(s, &Cuboid{}, bag.Cuboids[0].ID)).To(BeTrue())
+		})
+
+		It("Refuses to delete a bag at another version", func() {
+			w = testutils.MockRequestWithHeaders(s, http.MethodDelete, fmt.Sprintf("/bags/%v", bag.ID), nil, headers)
+			Expect(w.Code).To(Equal(412))
+			Expect(testutils.FindRecord(s, &Bag{}, bag.ID)).To(BeTrue())
+		})
+
+		It("Deletes a bag at the matching version", func() {
+			headers["If-Match"] = `"1"`
+			path := fmt.Sprintf("/bags/%v?cuboids=cascade", bag.ID)
+			w = testutils.MockRequestWithHeaders(s, http.MethodDelete, path, nil, headers)
+			Expect(w.Code).To(Equal(200))
+		})
+	})
+})
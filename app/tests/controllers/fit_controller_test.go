@@ -0,0 +1,111 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "cuboid-challenge/app/models"
+	"cuboid-challenge/app/store"
+	"cuboid-challenge/app/tests/testutils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fit Controller", func() {
+	testutils.LoadEnv()
+	var s store.Store
+
+	BeforeEach(func() {
+		s = store.NewMemory()
+	})
+
+	var w *httptest.ResponseRecorder
+	var bag, roomy, tight, disabled *Bag
+	var bagID uint
+	var query string
+
+	BeforeEach(func() {
+		bag = &Bag{Title: "A bag", Volume: 5, Cuboids: []Cuboid{{Width: 1, Height: 1, Depth: 4}}}
+		roomy = &Bag{Title: "Roomy", Volume: 100}
+		tight = &Bag{Title: "Tight", Volume: 10}
+		disabled = &Bag{Title: "Disabled", Volume: 100, Disabled: true}
+		testutils.AddRecords(s, bag, roomy, tight, disabled)
+		bagID = bag.ID
+		query = "width=1&height=1&depth=1"
+	})
+
+	JustBeforeEach(func() {
+		w = testutils.MockRequest(s, http.MethodGet, fmt.Sprintf("/bags/%v/fit?%s", bagID, query), nil)
+	})
+
+	Context("When the cuboid fits", func() {
+		It("Response HTTP status code 200", func() {
+			Expect(w.Code).To(Equal(200))
+		})
+
+		It("Answers it fits", func() {
+			m, _ := testutils.Deserialize(w.Body.String())
+			Expect(m["fits"]).To(BeTrue())
+			Expect(m["reason"]).To(BeNil())
+			Expect(m["reasonCode"]).To(BeNil())
+			Expect(m["alternatives"]).To(BeEmpty())
+		})
+	})
+
+	Context("When the cuboid does not fit", func() {
+		BeforeEach(func() {
+			query = "width=2&height=2&depth=2"
+		})
+
+		It("Answers why and ranks the alternatives", func() {
+			m, _ := testutils.Deserialize(w.Body.String())
+			Expect(m["fits"]).To(BeFalse())
+			Expect(m["reason"]).To(Equal("Insufficient capacity in bag"))
+			Expect(m["reasonCode"]).To(Equal("insufficient_capacity"))
+
+			alternatives := m["alternatives"].([]interface{})
+			Expect(alternatives).To(HaveLen(2))
+			Expect(alternatives[0]).To(HaveKeyWithValue("id", BeEquivalentTo(tight.ID)))
+			Expect(alternatives[1]).To(HaveKeyWithValue("id", BeEquivalentTo(roomy.ID)))
+		})
+
+		It("Does not create the cuboid", func() {
+			Expect(testutils.CountCuboids(s, 0)).To(BeEquivalentTo(1))
+		})
+	})
+
+	Context("When the bag is disabled", func() {
+		BeforeEach(func() {
+			bagID = disabled.ID
+		})
+
+		It("Answers the bag is disabled", func() {
+			m, _ := testutils.Deserialize(w.Body.String())
+			Expect(m["fits"]).To(BeFalse())
+			Expect(m["reason"]).To(Equal("Bag is disabled"))
+			Expect(m["reasonCode"]).To(Equal("bag_disabled"))
+		})
+	})
+
+	Context("When the dimensions are missing", func() {
+		BeforeEach(func() {
+			query = "width=1"
+		})
+
+		It("Response HTTP status code 400", func() {
+			Expect(w.Code).To(Equal(400))
+		})
+	})
+
+	Context("When the bag is not present", func() {
+		BeforeEach(func() {
+			bagID = 9999
+		})
+
+		It("Response HTTP status code 404", func() {
+			Expect(w.Code).To(Equal(404))
+		})
+	})
+})
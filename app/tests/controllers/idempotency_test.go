@@ -0,0 +1,177 @@
+package controllers_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"cuboid-challenge/app/controller"
+	. "cuboid-challenge/app/models"
+	"cuboid-challenge/app/router"
+	"cuboid-challenge/app/store"
+	"cuboid-challenge/app/tests/testutils"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// unfinishedStore fails to store the responses to the requests with an Idempotency-Key.
+type unfinishedStore struct {
+	store.Store
+}
+
+func (s unfinishedStore) IdempotencyKeys() store.IdempotencyKeyStore {
+	return unfinishedKeys{s.Store.IdempotencyKeys()}
+}
+
+type unfinishedKeys struct {
+	store.IdempotencyKeyStore
+}
+
+func (unfinishedKeys) Finish(*IdempotencyKey) error {
+	return errors.New("failure")
+}
+
+var _ = Describe("Idempotency keys", func() {
+	testutils.LoadEnv()
+	var s store.Store
+
+	BeforeEach(func() {
+		s = store.NewMemory()
+	})
+
+	var w *httptest.ResponseRecorder
+	var bag *Bag
+	var body string
+	headers := map[string]string{"Idempotency-Key": "scan-42"}
+
+	BeforeEach(func() {
+		bag = &Bag{Title: "A bag", Volume: 10}
+		testutils.AddRecords(s, bag)
+		body = fmt.Sprintf(`{"width": 1, "height": 1, "depth": 1, "bagId": %v}`, bag.ID)
+	})
+
+	Context("When a request is retried", func() {
+		var first *httptest.ResponseRecorder
+
+		BeforeEach(func() {
+			first = testutils.MockRequestWithHeaders(s, http.MethodPost, "/cuboids", &body, headers)
+			w = testutils.MockRequestWithHeaders(s, http.MethodPost, "/cuboids", &body, headers)
+		})
+
+		It("Replays the original response", func() {
+			Expect(first.Code).To(Equal(201))
+			Expect(w.Code).To(Equal(201))
+			Expect(w.Body.String()).To(Equal(first.Body.String()))
+			Expect(w.Header().Get("ETag")).To(Equal(first.Header().Get("ETag")))
+			Expect(w.Header().Get("Idempotent-Replayed")).To(Equal("true"))
+			Expect(testutils.CountCuboids(s, 0)).To(BeEquivalentTo(1))
+		})
+	})
+
+	Context("When the key is reused with a different body", func() {
+		BeforeEach(func() {
+			testutils.MockRequestWithHeaders(s, http.MethodPost, "/cuboids", &body, headers)
+			other := fmt.Sprintf(`{"width": 2, "height": 1, "depth": 1, "bagId": %v}`, bag.ID)
+			w = testutils.MockRequestWithHeaders(s, http.MethodPost, "/cuboids", &other, headers)
+		})
+
+		It("Response HTTP status code 422", func() {
+			Expect(w.Code).To(Equal(422))
+			Expect(testutils.CountCuboids(s, 0)).To(BeEquivalentTo(1))
+		})
+	})
+
+	Context("When the key is reused on another endpoint", func() {
+		BeforeEach(func() {
+			testutils.MockRequestWithHeaders(s, http.MethodPost, "/cuboids", &body, headers)
+			w = testutils.MockRequestWithHeaders(s, http.MethodPost, "/bags", &body, headers)
+		})
+
+		It("Response HTTP status code 422", func() {
+			Expect(w.Code).To(Equal(422))
+		})
+	})
+
+	Context("When the stored response is older than the window", func() {
+		BeforeEach(func() {
+			testutils.MockRequestWithHeaders(s, http.MethodPost, "/cuboids", &body, headers)
+			stored, err := s.IdempotencyKeys().Get(headers["Idempotency-Key"])
+			Expect(err).NotTo(HaveOccurred())
+			aged := stored
+			aged.CreatedAt = stored.CreatedAt.Add(-48 * time.Hour)
+			Expect(s.IdempotencyKeys().Replace(stored.CreatedAt, &aged)).To(BeTrue())
+			Expect(s.IdempotencyKeys().Finish(&aged)).To(Succeed())
+			w = testutils.MockRequestWithHeaders(s, http.MethodPost, "/cuboids", &body, headers)
+		})
+
+		It("Runs the request again", func() {
+			Expect(w.Code).To(Equal(201))
+			Expect(w.Header().Get("Idempotent-Replayed")).To(BeEmpty())
+			Expect(testutils.CountCuboids(s, 0)).To(BeEquivalentTo(2))
+		})
+	})
+
+	Context("When the request failed on the client side", func() {
+		BeforeEach(func() {
+			body = `{"width": 1, "height": 1, "depth": 1, "bagId": 99999}`
+			testutils.MockRequestWithHeaders(s, http.MethodPost, "/cuboids", &body, headers)
+			w = testutils.MockRequestWithHeaders(s, http.MethodPost, "/cuboids", &body, headers)
+		})
+
+		It("Replays the error", func() {
+			Expect(w.Code).To(Equal(404))
+			Expect(w.Header().Get("Idempotent-Replayed")).To(Equal("true"))
+			Expect(w.Header().Get("Content-Type")).To(Equal("application/problem+json"))
+		})
+	})
+
+	Context("When the handler panics", func() {
+		var r *gin.Engine
+
+		BeforeEach(func() {
+			r = gin.New()
+			r.Use(gin.Recovery())
+			r.POST("/panics", controller.New(s).Idempotent, func(*gin.Context) { panic("boom") })
+
+			testutils.ServeRequest(r, http.MethodPost, "/panics", &body, headers)
+			w = testutils.ServeRequest(r, http.MethodPost, "/panics", &body, headers)
+		})
+
+		It("Frees the key for a retry", func() {
+			Expect(w.Code).To(Equal(500))
+		})
+	})
+
+	Context("When the response can not be stored", func() {
+		var r *gin.Engine
+
+		BeforeEach(func() {
+			r = router.Setup(unfinishedStore{s})
+			bagBody := `{"title": "A bag", "volume": 10}`
+
+			testutils.ServeRequest(r, http.MethodPost, "/bags", &bagBody, headers)
+			w = testutils.ServeRequest(r, http.MethodPost, "/bags", &bagBody, headers)
+		})
+
+		It("Frees the key for a retry", func() {
+			Expect(w.Code).To(Equal(201))
+			Expect(w.Header().Get("Idempotent-Replayed")).To(BeEmpty())
+		})
+	})
+
+	Context("Without the header", func() {
+		BeforeEach(func() {
+			testutils.MockRequest(s, http.MethodPost, "/cuboids", &body)
+			w = testutils.MockRequest(s, http.MethodPost, "/cuboids", &body)
+		})
+
+		It("Creates a cuboid every time", func() {
+			Expect(w.Code).To(Equal(201))
+			Expect(testutils.CountCuboids(s, 0)).To(BeEquivalentTo(2))
+		})
+	})
+})
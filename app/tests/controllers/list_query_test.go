@@ -0,0 +1,120 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	. "cuboid-challenge/app/models"
+	"cuboid-challenge/app/store"
+	"cuboid-challenge/app/tests/testutils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("List query", func() {
+	testutils.LoadEnv()
+	var s store.Store
+
+	BeforeEach(func() {
+		s = store.NewMemory()
+	})
+
+	var w *httptest.ResponseRecorder
+	var bags []*Bag
+
+	BeforeEach(func() {
+		bags = []*Bag{
+			{Title: "a", Volume: 10, Cuboids: []Cuboid{{Width: 2, Height: 2, Depth: 2}}},
+			{Title: "b", Volume: 20, Cuboids: []Cuboid{{Width: 1, Height: 1, Depth: 1}}},
+			{Title: "c", Volume: 5},
+			{Title: "d", Volume: 30, Disabled: true},
+			{Title: "e", Volume: 20},
+		}
+		for _, bag := range bags {
+			testutils.AddRecords(s, bag)
+		}
+	})
+
+	// walk requests every page of the list and returns the ids in the order they came.
+	walk := func(path string, query url.Values) []interface{} {
+		var ids []interface{}
+		for {
+			w = testutils.MockRequest(s, http.MethodGet, path+"?"+query.Encode(), nil)
+			Expect(w.Code).To(Equal(200))
+
+			l, _ := testutils.DeserializeList(w.Body.String())
+			for _, m := range l {
+				ids = append(ids, m["id"])
+			}
+
+			next := w.Header().Get("X-Next-Cursor")
+			if next == "" {
+				return ids
+			}
+			query.Set("cursor", next)
+		}
+	}
+
+	id := func(bag *Bag) interface{} { return float64(bag.ID) }
+
+	Describe("Bags", func() {
+		It("Pages through every bag", func() {
+			ids := walk("/bags", url.Values{"limit": {"2"}})
+			Expect(ids).To(Equal([]interface{}{id(bags[0]), id(bags[1]), id(bags[2]), id(bags[3]), id(bags[4])}))
+			Expect(w.Header().Get("X-Total-Count")).To(Equal("5"))
+		})
+
+		It("Sorts by descending available volume", func() {
+			ids := walk("/bags", url.Values{"limit": {"2"}, "sort": {"-availableVolume"}})
+			Expect(ids).To(Equal([]interface{}{id(bags[3]), id(bags[4]), id(bags[1]), id(bags[2]), id(bags[0])}))
+		})
+
+		It("Filters by volume and available volume", func() {
+			ids := walk("/bags", url.Values{"minVolume": {"10"}, "maxAvailableVolume": {"19"}, "disabled": {"false"}})
+			Expect(ids).To(Equal([]interface{}{id(bags[0]), id(bags[1])}))
+			Expect(w.Header().Get("X-Total-Count")).To(Equal("2"))
+		})
+
+		It("Returns the cuboids of the page bags", func() {
+			w = testutils.MockRequest(s, http.MethodGet, "/bags?limit=1", nil)
+			l, _ := testutils.DeserializeList(w.Body.String())
+			Expect(l).To(HaveLen(1))
+			Expect(l[0]["cuboids"]).To(HaveLen(1))
+		})
+	})
+
+	Describe("Cuboids", func() {
+		It("Filters by bag", func() {
+			ids := walk("/cuboids", url.Values{"bagId": {fmt.Sprint(bags[1].ID)}})
+			Expect(ids).To(Equal([]interface{}{float64(bags[1].Cuboids[0].ID)}))
+		})
+
+		It("Sorts by volume", func() {
+			ids := walk("/cuboids", url.Values{"limit": {"1"}, "sort": {"volume"}})
+			Expect(ids).To(Equal([]interface{}{float64(bags[1].Cuboids[0].ID), float64(bags[0].Cuboids[0].ID)}))
+			Expect(w.Header().Get("X-Total-Count")).To(Equal("2"))
+		})
+	})
+
+	Describe("Invalid queries", func() {
+		for _, query := range []string{"limit=0", "limit=x", "sort=weight", "minVolume=-1", "disabled=maybe", "cursor=nope"} {
+			query := query
+
+			It(fmt.Sprintf("Responds 400 to %s", query), func() {
+				w = testutils.MockRequest(s, http.MethodGet, "/bags?"+query, nil)
+				Expect(w.Code).To(Equal(400))
+			})
+		}
+
+		It("Refuses a cursor issued for another sort", func() {
+			w = testutils.MockRequest(s, http.MethodGet, "/bags?limit=1", nil)
+			next := w.Header().Get("X-Next-Cursor")
+
+			w = testutils.MockRequest(s, http.MethodGet, "/bags?sort=volume&cursor="+next, nil)
+			Expect(w.Code).To(Equal(400))
+		})
+	})
+})
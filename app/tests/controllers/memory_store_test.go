@@ -0,0 +1,80 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"cuboid-challenge/app/router"
+	"cuboid-challenge/app/store"
+	"cuboid-challenge/app/tests/testutils"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Memory store", func() {
+	var r *gin.Engine
+
+	BeforeEach(func() {
+		r = router.Setup(store.NewMemory())
+	})
+
+	request := func(r *gin.Engine, method, path, body string) (int, map[string]interface{}) {
+		var b *string
+		if body != "" {
+			b = &body
+		}
+		w := testutils.ServeRequest(r, method, path, b, nil)
+		m, _ := testutils.Deserialize(w.Body.String())
+
+		return w.Code, m
+	}
+
+	It("Serves the bag and cuboid routes", func() {
+		code, bag := request(r, http.MethodPost, "/bags", `{"title": "A bag", "volume": 60}`)
+		Expect(code).To(Equal(201))
+
+		body := fmt.Sprintf(`{"width": 2, "height": 5, "depth": 5, "bagId": %v}`, bag["id"])
+		code, cuboid := request(r, http.MethodPost, "/cuboids", body)
+		Expect(code).To(Equal(201))
+
+		code, _ = request(r, http.MethodPost, "/cuboids", body)
+		Expect(code).To(Equal(400))
+
+		code, stored := request(r, http.MethodGet, fmt.Sprintf("/bags/%v", bag["id"]), "")
+		Expect(code).To(Equal(200))
+		Expect(stored["payloadVolume"]).To(BeEquivalentTo(50))
+
+		code, _ = request(r, http.MethodDelete, fmt.Sprintf("/bags/%v?cuboids=cascade", bag["id"]), "")
+		Expect(code).To(Equal(200))
+
+		code, _ = request(r, http.MethodGet, fmt.Sprintf("/cuboids/%v", cuboid["id"]), "")
+		Expect(code).To(Equal(404))
+
+		code, restored := request(r, http.MethodPost, fmt.Sprintf("/bags/%v/restore", bag["id"]), "")
+		Expect(code).To(Equal(200))
+		Expect(restored["cuboids"]).To(HaveLen(1))
+	})
+
+	It("Keeps every store apart", func() {
+		var wg sync.WaitGroup
+		codes := make([]int, 4)
+		for i := range codes {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				defer GinkgoRecover()
+
+				r := router.Setup(store.NewMemory())
+				_, bag := request(r, http.MethodPost, "/bags", `{"title": "Own bag", "volume": 8}`)
+				body := fmt.Sprintf(`{"width": 2, "height": 2, "depth": 2, "bagId": %v}`, bag["id"])
+				codes[i], _ = request(r, http.MethodPost, "/cuboids", body)
+			}(i)
+		}
+		wg.Wait()
+
+		Expect(codes).To(Equal([]int{201, 201, 201, 201}))
+	})
+})
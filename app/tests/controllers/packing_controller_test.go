@@ -0,0 +1,146 @@
+package controllers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	. "cuboid-challenge/app/models"
+	"cuboid-challenge/app/store"
+	"cuboid-challenge/app/tests/testutils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Packing Controller", func() {
+	testutils.LoadEnv()
+	var s store.Store
+
+	BeforeEach(func() {
+		s = store.NewMemory()
+	})
+
+	var w *httptest.ResponseRecorder
+	var small, big, disabled *Bag
+	var payload map[string]interface{}
+
+	BeforeEach(func() {
+		small = &Bag{Title: "Small", Volume: 8, Width: 2, Height: 2, Depth: 2}
+		big = &Bag{Title: "Big", Volume: 30}
+		disabled = &Bag{Title: "Disabled", Volume: 100, Disabled: true}
+		testutils.AddRecords(s, small, big, disabled)
+
+		payload = map[string]interface{}{
+			"cuboids": []map[string]interface{}{
+				{"width": 2, "height": 2, "depth": 2},
+				{"width": 3, "height": 3, "depth": 3},
+				{"width": 5, "height": 5, "depth": 5},
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		body, _ := testutils.SerializeToString(payload)
+		w = testutils.MockRequest(s, http.MethodPost, "/packings", &body)
+	})
+
+	It("Response HTTP status code 200", func() {
+		Expect(w.Code).To(Equal(200))
+	})
+
+	It("Assigns the cuboids to the enabled bags", func() {
+		m, _ := testutils.Deserialize(w.Body.String())
+		Expect(m["strategy"]).To(Equal("first-fit-decreasing"))
+		Expect(m["committed"]).To(BeFalse())
+
+		assignments := m["assignments"].([]interface{})
+		Expect(assignments).To(HaveLen(2))
+		Expect(assignments[0]).To(HaveKeyWithValue("bagId", BeEquivalentTo(small.ID)))
+		Expect(assignments[1]).To(HaveKeyWithValue("bagId", BeEquivalentTo(big.ID)))
+
+		unplaced := m["unplaced"].([]interface{})
+		Expect(unplaced).To(HaveLen(1))
+		Expect(unplaced[0]).To(HaveKeyWithValue("index", BeEquivalentTo(2)))
+	})
+
+	It("Does not create the cuboids", func() {
+		Expect(testutils.CountCuboids(s, 0)).To(BeZero())
+	})
+
+	Context("When the assignment is committed", func() {
+		BeforeEach(func() {
+			payload["commit"] = true
+		})
+
+		It("Response HTTP status code 201", func() {
+			Expect(w.Code).To(Equal(201))
+		})
+
+		It("Creates the assigned cuboids", func() {
+			cuboids, _, _, err := s.Cuboids().List(store.ListQuery{Limit: 10, Sort: "id"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cuboids).To(HaveLen(2))
+			Expect(cuboids[0].BagID).To(Equal(small.ID))
+			Expect(cuboids[0].Orientation).To(BeEquivalentTo("WHD"))
+		})
+	})
+
+	Context("When candidate bags are given", func() {
+		BeforeEach(func() {
+			payload["bagIds"] = []uint{big.ID}
+		})
+
+		It("Only uses those bags", func() {
+			m, _ := testutils.Deserialize(w.Body.String())
+			Expect(m["assignments"]).To(HaveLen(1))
+			Expect(m["unplaced"]).To(HaveLen(2))
+		})
+	})
+
+	Context("When a candidate bag is not present", func() {
+		BeforeEach(func() {
+			payload["bagIds"] = []uint{9999}
+		})
+
+		It("Response HTTP status code 404", func() {
+			Expect(w.Code).To(Equal(404))
+		})
+	})
+
+	Context("When there are too many cuboids", func() {
+		BeforeEach(func() {
+			cuboids := make([]map[string]interface{}, 1001)
+			for i := range cuboids {
+				cuboids[i] = map[string]interface{}{"width": 1, "height": 1, "depth": 1}
+			}
+			payload["cuboids"] = cuboids
+		})
+
+		It("Response HTTP status code 400", func() {
+			Expect(w.Code).To(Equal(400))
+
+			m, _ := testutils.Deserialize(w.Body.String())
+			Expect(m["code"]).To(Equal("invalid_bulk_size"))
+		})
+	})
+
+	Context("When there is no cuboid", func() {
+		BeforeEach(func() {
+			payload["cuboids"] = []map[string]interface{}{}
+		})
+
+		It("Response HTTP status code 400", func() {
+			Expect(w.Code).To(Equal(400))
+		})
+	})
+
+	Context("When the strategy is unknown", func() {
+		BeforeEach(func() {
+			payload["strategy"] = "random"
+		})
+
+		It("Response HTTP status code 400", func() {
+			Expect(w.Code).To(Equal(400))
+		})
+	})
+})
@@ -0,0 +1,131 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "cuboid-challenge/app/models"
+	"cuboid-challenge/app/store"
+	"cuboid-challenge/app/tests/testutils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Problem responses", func() {
+	testutils.LoadEnv()
+	var s store.Store
+
+	BeforeEach(func() {
+		s = store.NewMemory()
+	})
+
+	var w *httptest.ResponseRecorder
+	var bag *Bag
+
+	BeforeEach(func() {
+		bag = &Bag{Title: "A bag", Volume: 1}
+		testutils.AddRecords(s, bag)
+	})
+
+	Context("When the bag has no room", func() {
+		BeforeEach(func() {
+			body := fmt.Sprintf(`{"width": 2, "height": 1, "depth": 1, "bagId": %v}`, bag.ID)
+			w = testutils.MockRequest(s, http.MethodPost, "/cuboids", &body)
+		})
+
+		It("Responds with the documented problem", func() {
+			Expect(w.Code).To(Equal(400))
+			Expect(w.Header().Get("Content-Type")).To(HavePrefix("application/problem+json"))
+
+			m, _ := testutils.Deserialize(w.Body.String())
+			Expect(m["type"]).To(Equal("/problems/insufficient_capacity"))
+			Expect(m["code"]).To(Equal("insufficient_capacity"))
+			Expect(m["status"]).To(BeEquivalentTo(400))
+			Expect(m["title"]).To(Equal("Insufficient capacity in bag"))
+			Expect(m["detail"]).To(Equal("Insufficient capacity in bag"))
+			Expect(m["instance"]).To(Equal("/cuboids"))
+		})
+	})
+
+	Context("When the input is not valid", func() {
+		BeforeEach(func() {
+			body := fmt.Sprintf(`{"width": 0, "height": 1, "depth": 0, "bagId": %v}`, bag.ID)
+			w = testutils.MockRequest(s, http.MethodPost, "/cuboids", &body)
+		})
+
+		It("Lists every field error", func() {
+			Expect(w.Code).To(Equal(400))
+
+			m, _ := testutils.Deserialize(w.Body.String())
+			Expect(m["code"]).To(Equal("validation_failed"))
+			Expect(m["errors"]).To(ConsistOf(
+				map[string]interface{}{
+					"field": "width", "type": "gt", "param": "0",
+					"message": "width must be greater than 0",
+				},
+				map[string]interface{}{
+					"field": "depth", "type": "gt", "param": "0",
+					"message": "depth must be greater than 0",
+				},
+			))
+		})
+	})
+
+	Context("When the client prefers Spanish", func() {
+		BeforeEach(func() {
+			body := fmt.Sprintf(`{"width": 0, "height": 1, "depth": 1, "bagId": %v}`, bag.ID)
+			w = testutils.MockRequestWithHeaders(s, http.MethodPost, "/cuboids", &body, map[string]string{
+				"Accept-Language": "es-ES, en;q=0.5",
+			})
+		})
+
+		It("Translates the validation messages", func() {
+			Expect(w.Header().Get("Content-Language")).To(Equal("es"))
+
+			m, _ := testutils.Deserialize(w.Body.String())
+			Expect(m["detail"]).To(Equal("width debe ser mayor que 0"))
+		})
+	})
+
+	Context("When a list query is not valid", func() {
+		BeforeEach(func() {
+			w = testutils.MockRequest(s, http.MethodGet, "/bags?sort=weight", nil)
+		})
+
+		It("Tells what is wrong in the detail", func() {
+			m, _ := testutils.Deserialize(w.Body.String())
+			Expect(m["code"]).To(Equal("invalid_sort"))
+			Expect(m["detail"]).To(Equal(`Invalid sort key "weight"`))
+		})
+	})
+
+	Describe("Catalog", func() {
+		It("Lists every problem type once", func() {
+			w = testutils.MockRequest(s, http.MethodGet, "/problems", nil)
+			Expect(w.Code).To(Equal(200))
+
+			l, _ := testutils.DeserializeList(w.Body.String())
+			codes := map[interface{}]bool{}
+			for _, p := range l {
+				Expect(codes).NotTo(HaveKey(p["code"]))
+				codes[p["code"]] = true
+			}
+			Expect(codes).To(HaveKey("bag_disabled"))
+		})
+
+		It("Documents a problem type", func() {
+			w = testutils.MockRequest(s, http.MethodGet, "/problems/version_mismatch", nil)
+			Expect(w.Code).To(Equal(200))
+
+			m, _ := testutils.Deserialize(w.Body.String())
+			Expect(m["status"]).To(BeEquivalentTo(412))
+		})
+
+		It("Response HTTP status code 404 for an unknown code", func() {
+			w = testutils.MockRequest(s, http.MethodGet, "/problems/nope", nil)
+			Expect(w.Code).To(Equal(404))
+		})
+	})
+})
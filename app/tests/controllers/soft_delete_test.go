@@ -0,0 +1,152 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "cuboid-challenge/app/models"
+	"cuboid-challenge/app/store"
+	"cuboid-challenge/app/tests/testutils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Soft delete", func() {
+	testutils.LoadEnv()
+	var s store.Store
+
+	BeforeEach(func() {
+		s = store.NewMemory()
+	})
+
+	var w *httptest.ResponseRecorder
+	var bag *Bag
+
+	BeforeEach(func() {
+		bag = &Bag{
+			Title:   "A bag",
+			Volume:  10,
+			Cuboids: []Cuboid{{Width: 1, Height: 1, Depth: 1}, {Width: 1, Height: 1, Depth: 2}},
+		}
+		testutils.AddRecords(s, bag)
+	})
+
+	deleteBag := func() {
+		w = testutils.MockRequest(s, http.MethodDelete, fmt.Sprintf("/bags/%v?cuboids=cascade", bag.ID), nil)
+		Expect(w.Code).To(Equal(200))
+	}
+
+	Describe("Reads", func() {
+		BeforeEach(deleteBag)
+
+		It("Hides the deleted bag", func() {
+			w = testutils.MockRequest(s, http.MethodGet, fmt.Sprintf("/bags/%v", bag.ID), nil)
+			Expect(w.Code).To(Equal(404))
+
+			w = testutils.MockRequest(s, http.MethodGet, "/bags", nil)
+			l, _ := testutils.DeserializeList(w.Body.String())
+			Expect(l).To(BeEmpty())
+		})
+
+		It("Returns the deleted bag and cuboids when asked for", func() {
+			w = testutils.MockRequest(s, http.MethodGet, fmt.Sprintf("/bags/%v?includeDeleted=true", bag.ID), nil)
+			Expect(w.Code).To(Equal(200))
+
+			m, _ := testutils.Deserialize(w.Body.String())
+			Expect(m["deletedAt"]).NotTo(BeNil())
+			Expect(m["cuboids"]).To(HaveLen(2))
+
+			w = testutils.MockRequest(s, http.MethodGet, "/cuboids?includeDeleted=true", nil)
+			l, _ := testutils.DeserializeList(w.Body.String())
+			Expect(l).To(HaveLen(2))
+		})
+	})
+
+	Describe("Restore bag", func() {
+		JustBeforeEach(func() {
+			w = testutils.MockRequest(s, http.MethodPost, fmt.Sprintf("/bags/%v/restore", bag.ID), nil)
+		})
+
+		Context("When the bag was deleted with its cuboids", func() {
+			var earlier Cuboid
+
+			BeforeEach(func() {
+				earlier = bag.Cuboids[1]
+				r := testutils.MockRequest(s, http.MethodDelete, fmt.Sprintf("/cuboids/%v", earlier.ID), nil)
+				Expect(r.Code).To(Equal(200))
+				deleteBag()
+			})
+
+			It("Restores the bag and the cuboids deleted along with it", func() {
+				Expect(w.Code).To(Equal(200))
+
+				m, _ := testutils.Deserialize(w.Body.String())
+				Expect(m["deletedAt"]).To(BeNil())
+				Expect(m["cuboids"]).To(HaveLen(1))
+				Expect(testutils.FindRecord(s, &Cuboid{}, bag.Cuboids[0].ID)).To(BeTrue())
+				Expect(testutils.FindRecord(s, &Cuboid{}, earlier.ID)).To(BeFalse())
+			})
+		})
+
+		Context("When the bag is not deleted", func() {
+			It("Response HTTP status code 409", func() {
+				Expect(w.Code).To(Equal(409))
+			})
+		})
+	})
+
+	Describe("Restore cuboid", func() {
+		var cuboid Cuboid
+
+		BeforeEach(func() {
+			cuboid = bag.Cuboids[1]
+			r := testutils.MockRequest(s, http.MethodDelete, fmt.Sprintf("/cuboids/%v", cuboid.ID), nil)
+			Expect(r.Code).To(Equal(200))
+		})
+
+		JustBeforeEach(func() {
+			w = testutils.MockRequest(s, http.MethodPost, fmt.Sprintf("/cuboids/%v/restore", cuboid.ID), nil)
+		})
+
+		It("Restores the cuboid", func() {
+			Expect(w.Code).To(Equal(200))
+			Expect(testutils.FindRecord(s, &Cuboid{}, cuboid.ID)).To(BeTrue())
+		})
+
+		Context("When its room was taken in the meantime", func() {
+			BeforeEach(func() {
+				testutils.AddRecords(s, &Cuboid{Width: 2, Height: 2, Depth: 2, BagID: bag.ID})
+			})
+
+			It("Response HTTP status code 400", func() {
+				Expect(w.Code).To(Equal(400))
+				Expect(testutils.FindRecord(s, &Cuboid{}, cuboid.ID)).To(BeFalse())
+			})
+		})
+
+		Context("When its bag is deleted", func() {
+			BeforeEach(func() {
+				r := testutils.MockRequest(s, http.MethodDelete, fmt.Sprintf("/cuboids/%v", bag.Cuboids[0].ID), nil)
+				Expect(r.Code).To(Equal(200))
+				r = testutils.MockRequest(s, http.MethodDelete, fmt.Sprintf("/bags/%v", bag.ID), nil)
+				Expect(r.Code).To(Equal(200))
+			})
+
+			It("Response HTTP status code 404", func() {
+				Expect(w.Code).To(Equal(404))
+			})
+		})
+
+		Context("When the cuboid is not deleted", func() {
+			BeforeEach(func() {
+				cuboid = bag.Cuboids[0]
+			})
+
+			It("Response HTTP status code 409", func() {
+				Expect(w.Code).To(Equal(409))
+			})
+		})
+	})
+})
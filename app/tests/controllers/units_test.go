@@ -0,0 +1,184 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "cuboid-challenge/app/models"
+	"cuboid-challenge/app/store"
+	"cuboid-challenge/app/tests/testutils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Units", func() {
+	testutils.LoadEnv()
+	var s store.Store
+
+	BeforeEach(func() {
+		s = store.NewMemory()
+	})
+
+	var w *httptest.ResponseRecorder
+	var bag *Bag
+	var body string
+
+	BeforeEach(func() {
+		bag = &Bag{Title: "A bag", Volume: 1_000_000}
+		testutils.AddRecords(s, bag)
+	})
+
+	Describe("Create cuboid", func() {
+		JustBeforeEach(func() {
+			w = testutils.MockRequest(s, http.MethodPost, "/cuboids", &body)
+		})
+
+		Context("When the sizes are in centimetres", func() {
+			BeforeEach(func() {
+				body = fmt.Sprintf(`{"width": 2.5, "height": 1, "depth": 0.4, "unit": "cm", "bagId": %v}`, bag.ID)
+			})
+
+			It("Stores them in millimetres", func() {
+				Expect(w.Code).To(Equal(201))
+
+				m, _ := testutils.Deserialize(w.Body.String())
+				var cuboid Cuboid
+				Expect(testutils.FindRecord(s, &cuboid, uint(m["id"].(float64)))).To(BeTrue())
+				Expect(cuboid.Width).To(BeEquivalentTo(25))
+				Expect(cuboid.Height).To(BeEquivalentTo(10))
+				Expect(cuboid.Depth).To(BeEquivalentTo(4))
+			})
+
+			It("Responds in the canonical unit", func() {
+				m, _ := testutils.Deserialize(w.Body.String())
+				Expect(m["unit"]).To(Equal("mm"))
+				Expect(m["width"]).To(BeEquivalentTo(25))
+				Expect(m["volume"]).To(BeEquivalentTo(1000))
+			})
+		})
+
+		Context("When a size loses precision", func() {
+			BeforeEach(func() {
+				body = fmt.Sprintf(`{"width": 1, "height": 1, "depth": 1, "unit": "in", "bagId": %v}`, bag.ID)
+			})
+
+			It("Rejects the cuboid", func() {
+				Expect(w.Code).To(Equal(400))
+
+				m, _ := testutils.Deserialize(w.Body.String())
+				Expect(m["code"]).To(Equal("validation_failed"))
+				Expect(m["detail"]).To(ContainSubstring("width must be a whole number of mm once converted"))
+			})
+		})
+
+		Context("When the unit is unknown", func() {
+			BeforeEach(func() {
+				body = fmt.Sprintf(`{"width": 1, "height": 1, "depth": 1, "unit": "ft", "bagId": %v}`, bag.ID)
+			})
+
+			It("Names the field", func() {
+				Expect(w.Code).To(Equal(400))
+
+				m, _ := testutils.Deserialize(w.Body.String())
+				Expect(m["field"]).To(Equal("unit"))
+				Expect(m["detail"]).To(Equal("unit must be one of mm, cm, in"))
+			})
+		})
+	})
+
+	Describe("Get bag", func() {
+		var path string
+		var headers map[string]string
+
+		BeforeEach(func() {
+			bag = &Bag{Title: "Sized", Volume: 8000, Width: 20, Height: 20, Depth: 20,
+				Cuboids: []Cuboid{{Width: 10, Height: 20, Depth: 5}}}
+			testutils.AddRecords(s, bag)
+			path = fmt.Sprintf("/bags/%v", bag.ID)
+			headers = map[string]string{}
+		})
+
+		JustBeforeEach(func() {
+			w = testutils.MockRequestWithHeaders(s, http.MethodGet, path, nil, headers)
+		})
+
+		Context("When the unit is in the query", func() {
+			BeforeEach(func() {
+				path += "?unit=cm"
+			})
+
+			It("Renders the bag and its cuboids in it", func() {
+				Expect(w.Code).To(Equal(200))
+
+				m, _ := testutils.Deserialize(w.Body.String())
+				Expect(m["unit"]).To(Equal("cm"))
+				Expect(m["width"]).To(BeEquivalentTo(2))
+				Expect(m["volume"]).To(BeEquivalentTo(8))
+
+				cuboid := m["cuboids"].([]interface{})[0].(map[string]interface{})
+				Expect(cuboid["unit"]).To(Equal("cm"))
+				Expect(cuboid["depth"]).To(BeEquivalentTo(0.5))
+				Expect(cuboid["volume"]).To(BeEquivalentTo(1))
+			})
+		})
+
+		Context("When the unit is in the header", func() {
+			BeforeEach(func() {
+				headers["X-Unit"] = "cm"
+			})
+
+			It("Renders the bag in it", func() {
+				m, _ := testutils.Deserialize(w.Body.String())
+				Expect(m["unit"]).To(Equal("cm"))
+			})
+		})
+
+		Context("When the unit is unknown", func() {
+			BeforeEach(func() {
+				path += "?unit=ft"
+			})
+
+			It("Responds with an unknown unit problem", func() {
+				Expect(w.Code).To(Equal(400))
+
+				m, _ := testutils.Deserialize(w.Body.String())
+				Expect(m["code"]).To(Equal("unknown_unit"))
+			})
+		})
+	})
+
+	Describe("Patch cuboid", func() {
+		var cuboid *Cuboid
+
+		BeforeEach(func() {
+			cuboid = &Cuboid{Width: 10, Height: 10, Depth: 10, BagID: bag.ID}
+			testutils.AddRecords(s, cuboid)
+			body = `{"width": 2, "unit": "cm"}`
+			w = testutils.MockRequest(s, http.MethodPatch, fmt.Sprintf("/cuboids/%v", cuboid.ID), &body)
+		})
+
+		It("Converts the patched sizes only", func() {
+			Expect(w.Code).To(Equal(200))
+
+			m, _ := testutils.Deserialize(w.Body.String())
+			Expect(m["width"]).To(BeEquivalentTo(20))
+			Expect(m["height"]).To(BeEquivalentTo(10))
+		})
+	})
+
+	Describe("Create bag", func() {
+		BeforeEach(func() {
+			body = `{"title": "Inches", "volume": 125, "unit": "in"}`
+			w = testutils.MockRequest(s, http.MethodPost, "/bags", &body)
+		})
+
+		It("Converts the volume with the cube of the unit", func() {
+			Expect(w.Code).To(Equal(201))
+
+			m, _ := testutils.Deserialize(w.Body.String())
+			Expect(m["volume"]).To(BeEquivalentTo(2048383))
+		})
+	})
+})
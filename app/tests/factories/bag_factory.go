@@ -1,6 +1,8 @@
 package factories
 
 import (
+	"math"
+
 	"cuboid-challenge/app/models"
 
 	"github.com/brianvoe/gofakeit/v6"
@@ -9,6 +11,6 @@ import (
 func Bag() *models.Bag {
 	return &models.Bag{
 		Title:  gofakeit.Name(),
-		Volume: uint(gofakeit.Uint32()),
+		Volume: uint(gofakeit.Number(1, math.MaxInt32)),
 	}
 }
@@ -8,8 +8,8 @@ import (
 
 func Cuboid() *models.Cuboid {
 	return &models.Cuboid{
-		Width:  uint(gofakeit.Uint32()),
-		Height: uint(gofakeit.Uint32()),
-		Depth:  uint(gofakeit.Uint32()),
+		Width:  uint(gofakeit.Number(1, models.MaxDimension)),
+		Height: uint(gofakeit.Number(1, models.MaxDimension)),
+		Depth:  uint(gofakeit.Number(1, models.MaxDimension)),
 	}
 }
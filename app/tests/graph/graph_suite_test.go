@@ -0,0 +1,16 @@
+package graph_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/reporters"
+	. "github.com/onsi/gomega"
+)
+
+func TestGraph(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	junitReporter := reporters.NewJUnitReporter("reports/graph.xml")
+	RunSpecsWithDefaultAndCustomReporters(t, "Graph Suite", []Reporter{junitReporter})
+}
@@ -0,0 +1,250 @@
+package graph_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+
+	"cuboid-challenge/app/i18n"
+	"cuboid-challenge/app/models"
+	"cuboid-challenge/app/router"
+	"cuboid-challenge/app/store"
+	"cuboid-challenge/app/tests/testutils"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// countingStore counts the reads of the cuboids of several bags.
+type countingStore struct {
+	store.Store
+	reads *int32
+}
+
+func (s countingStore) Cuboids() store.CuboidStore {
+	return countingCuboids{s.Store.Cuboids(), s.reads}
+}
+
+type countingCuboids struct {
+	store.CuboidStore
+	reads *int32
+}
+
+func (c countingCuboids) InBags(bagIDs []uint, withDeleted bool) ([]models.Cuboid, error) {
+	atomic.AddInt32(c.reads, 1)
+
+	return c.CuboidStore.InBags(bagIDs, withDeleted)
+}
+
+type response struct {
+	Data   map[string]interface{}
+	Errors []struct {
+		Message    string
+		Extensions map[string]interface{}
+	}
+}
+
+var _ = Describe("GraphQL", func() {
+	testutils.LoadEnv()
+
+	var r *gin.Engine
+	var reads int32
+	var headers map[string]string
+
+	BeforeEach(func() {
+		reads, headers = 0, nil
+		r = router.Setup(countingStore{store.NewMemory(), &reads})
+	})
+
+	query := func(q string, variables map[string]interface{}) (int, response) {
+		b, err := json.Marshal(map[string]interface{}{"query": q, "variables": variables})
+		Expect(err).NotTo(HaveOccurred())
+
+		body := string(b)
+		w := testutils.ServeRequest(r, http.MethodPost, "/graphql", &body, headers)
+
+		var res response
+		Expect(json.Unmarshal(w.Body.Bytes(), &res)).To(Succeed())
+
+		return w.Code, res
+	}
+
+	mustQuery := func(q string, variables map[string]interface{}) map[string]interface{} {
+		code, res := query(q, variables)
+		Expect(code).To(Equal(200))
+		Expect(res.Errors).To(BeEmpty())
+
+		return res.Data
+	}
+
+	createBag := func(title string, volume float64) string {
+		data := mustQuery(`mutation($title: String!, $volume: Float) {
+			createBag(input: {title: $title, volume: $volume}) { id }
+		}`, map[string]interface{}{"title": title, "volume": volume})
+
+		return data["createBag"].(map[string]interface{})["id"].(string)
+	}
+
+	createCuboid := func(bagID string, size int) string {
+		data := mustQuery(`mutation($bagId: ID!, $size: Int!) {
+			createCuboid(input: {width: $size, height: $size, depth: $size, bagId: $bagId}) { id }
+		}`, map[string]interface{}{"bagId": bagID, "size": size})
+
+		return data["createCuboid"].(map[string]interface{})["id"].(string)
+	}
+
+	Describe("Queries", func() {
+		It("Returns the selected fields of the bags and their cuboids", func() {
+			bagID := createBag("A bag", 100)
+			createCuboid(bagID, 2)
+			createCuboid(bagID, 3)
+
+			data := mustQuery(`{ bags { totalCount nodes { title payloadVolume availableVolume cuboids { volume } } } }`, nil)
+			bags := data["bags"].(map[string]interface{})
+			Expect(bags["totalCount"]).To(BeEquivalentTo(1))
+
+			bag := bags["nodes"].([]interface{})[0].(map[string]interface{})
+			Expect(bag).To(HaveLen(4))
+			Expect(bag["payloadVolume"]).To(BeEquivalentTo(35))
+			Expect(bag["availableVolume"]).To(BeEquivalentTo(65))
+			Expect(bag["cuboids"]).To(HaveLen(2))
+		})
+
+		It("Reads the cuboids of a page of bags at once", func() {
+			for i := 0; i < 5; i++ {
+				createCuboid(createBag("A bag", 100), 2)
+			}
+
+			data := mustQuery(`{ bags { nodes { availableVolume cuboids { id } } } }`, nil)
+			Expect(data["bags"].(map[string]interface{})["nodes"]).To(HaveLen(5))
+			Expect(atomic.LoadInt32(&reads)).To(BeEquivalentTo(1))
+		})
+
+		It("Does not read the cuboids when they are not selected", func() {
+			createCuboid(createBag("A bag", 100), 2)
+
+			mustQuery(`{ bags { nodes { id title } } }`, nil)
+			Expect(atomic.LoadInt32(&reads)).To(BeZero())
+		})
+
+		It("Pages, sorts and filters like the REST lists", func() {
+			createBag("a", 10)
+			createBag("b", 30)
+			createBag("c", 20)
+
+			data := mustQuery(`{ bags(first: 2, sort: "-volume") { nodes { title } nextCursor } }`, nil)
+			page := data["bags"].(map[string]interface{})
+			Expect(page["nodes"]).To(Equal([]interface{}{
+				map[string]interface{}{"title": "b"}, map[string]interface{}{"title": "c"},
+			}))
+			Expect(page["nextCursor"]).NotTo(BeNil())
+
+			data = mustQuery(`query($after: String) { bags(first: 2, sort: "-volume", after: $after) { nodes { title } nextCursor } }`,
+				map[string]interface{}{"after": page["nextCursor"]})
+			page = data["bags"].(map[string]interface{})
+			Expect(page["nodes"]).To(Equal([]interface{}{map[string]interface{}{"title": "a"}}))
+			Expect(page["nextCursor"]).To(BeNil())
+
+			data = mustQuery(`{ bags(filter: {minVolume: 20, maxVolume: 25}) { nodes { title } } }`, nil)
+			Expect(data["bags"].(map[string]interface{})["nodes"]).To(Equal([]interface{}{map[string]interface{}{"title": "c"}}))
+		})
+
+		It("Reports an invalid sort with its problem code", func() {
+			_, res := query(`{ bags(sort: "color") { totalCount } }`, nil)
+			Expect(res.Errors).To(HaveLen(1))
+			Expect(res.Errors[0].Extensions["code"]).To(Equal("invalid_sort"))
+		})
+
+		It("Answers null for a missing record", func() {
+			data := mustQuery(`{ bag(id: "1000") { id } cuboid(id: "x") { id } }`, nil)
+			Expect(data["bag"]).To(BeNil())
+			Expect(data["cuboid"]).To(BeNil())
+		})
+
+		It("Gets a cuboid", func() {
+			id := createCuboid(createBag("A bag", 100), 2)
+
+			data := mustQuery(`query($id: ID!) { cuboid(id: $id) { width volume placement { x } } }`, map[string]interface{}{"id": id})
+			Expect(data["cuboid"]).To(Equal(map[string]interface{}{"width": 2.0, "volume": 8.0, "placement": nil}))
+		})
+	})
+
+	Describe("Mutations", func() {
+		It("Reports the validation errors by field", func() {
+			_, res := query(`mutation { createBag(input: {title: "", volume: 10}) { id } }`, nil)
+			Expect(res.Errors).To(HaveLen(1))
+			Expect(res.Errors[0].Extensions["code"]).To(Equal("validation_failed"))
+			Expect(res.Errors[0].Extensions["errors"]).To(HaveLen(1))
+		})
+
+		It("Names the fields as in JSON, with the messages in the accepted language", func() {
+			headers = map[string]string{"Accept-Language": "es"}
+			_, res := query(`mutation { createBag(input: {title: "", volume: 10}) { id } }`, nil)
+			Expect(res.Errors).To(HaveLen(1))
+
+			fe := models.FieldError{Field: "Title", Type: "required"}
+			Expect(res.Errors[0].Message).To(Equal(models.ValidationErrors{fe}.Localize(i18n.Spanish)))
+			Expect(res.Errors[0].Extensions["errors"]).To(ConsistOf(map[string]interface{}{
+				"field": "title", "type": "required", "param": "", "message": fe.Localize(i18n.Spanish),
+			}))
+		})
+
+		It("Takes the volume of a bag from its dimensions when it is left out", func() {
+			data := mustQuery(`mutation { createBag(input: {title: "A bag", width: 2, height: 3, depth: 4}) { volume } }`, nil)
+			Expect(data["createBag"]).To(Equal(map[string]interface{}{"volume": 24.0}))
+		})
+
+		It("Rejects a cuboid larger than what is left", func() {
+			bagID := createBag("A bag", 10)
+
+			_, res := query(`mutation($bagId: ID!) { createCuboid(input: {width: 3, height: 3, depth: 3, bagId: $bagId}) { id } }`,
+				map[string]interface{}{"bagId": bagID})
+			Expect(res.Errors).To(HaveLen(1))
+			Expect(res.Errors[0].Extensions["code"]).To(Equal("insufficient_capacity"))
+			Expect(res.Errors[0].Extensions["status"]).To(BeEquivalentTo(400))
+		})
+
+		It("Updates a bag at the expected version", func() {
+			bagID := createBag("A bag", 100)
+
+			_, res := query(`mutation($id: ID!) { updateBag(id: $id, input: {title: "x"}, expectedVersion: 5) { id } }`,
+				map[string]interface{}{"id": bagID})
+			Expect(res.Errors[0].Extensions["code"]).To(Equal("version_mismatch"))
+
+			data := mustQuery(`mutation($id: ID!) { updateBag(id: $id, input: {disabled: true}, expectedVersion: 1) { title disabled version } }`,
+				map[string]interface{}{"id": bagID})
+			Expect(data["updateBag"]).To(Equal(map[string]interface{}{"title": "A bag", "disabled": true, "version": 2.0}))
+		})
+
+		It("Moves and deletes cuboids", func() {
+			bagID := createBag("A bag", 100)
+			otherID := createBag("Other", 100)
+			cuboidID := createCuboid(bagID, 2)
+
+			data := mustQuery(`mutation($id: ID!, $bagId: ID) { updateCuboid(id: $id, input: {width: 3, height: 3, depth: 3, bagId: $bagId}) { bagId volume } }`,
+				map[string]interface{}{"id": cuboidID, "bagId": otherID})
+			Expect(data["updateCuboid"]).To(Equal(map[string]interface{}{"bagId": otherID, "volume": 27.0}))
+
+			_, res := query(`mutation($id: ID!) { deleteBag(id: $id) }`, map[string]interface{}{"id": otherID})
+			Expect(res.Errors[0].Extensions["code"]).To(Equal("bag_not_empty"))
+
+			data = mustQuery(`mutation($id: ID!, $target: ID) { deleteBag(id: $id, cuboids: MOVE, targetBagId: $target) }`,
+				map[string]interface{}{"id": otherID, "target": bagID})
+			Expect(data["deleteBag"]).To(BeTrue())
+
+			data = mustQuery(`mutation($id: ID!) { deleteCuboid(id: $id) }`, map[string]interface{}{"id": cuboidID})
+			Expect(data["deleteCuboid"]).To(BeTrue())
+
+			data = mustQuery(`query($id: ID!) { bag(id: $id) { cuboids { id } } }`, map[string]interface{}{"id": bagID})
+			Expect(data["bag"].(map[string]interface{})["cuboids"]).To(BeEmpty())
+		})
+
+		It("Answers a 400 to a body that is not JSON", func() {
+			body := "{"
+			w := testutils.ServeRequest(r, http.MethodPost, "/graphql", &body, nil)
+			Expect(w.Code).To(Equal(400))
+			Expect(w.Body.String()).To(ContainSubstring(`"code":"invalid_body"`))
+		})
+	})
+})
@@ -0,0 +1,16 @@
+package i18n_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/reporters"
+	. "github.com/onsi/gomega"
+)
+
+func TestI18n(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	junitReporter := reporters.NewJUnitReporter("reports/i18n.xml")
+	RunSpecsWithDefaultAndCustomReporters(t, "I18n Suite", []Reporter{junitReporter})
+}
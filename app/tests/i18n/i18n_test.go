@@ -0,0 +1,45 @@
+package i18n_test
+
+import (
+	"cuboid-challenge/app/i18n"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("I18n", func() {
+	Describe("Negotiate", func() {
+		headers := map[string]i18n.Locale{
+			"":                         i18n.English,
+			"es":                       i18n.Spanish,
+			"es-AR":                    i18n.Spanish,
+			"fr, es;q=0.5":             i18n.Spanish,
+			"en;q=0.4, es;q=0.8":       i18n.Spanish,
+			"de, *;q=0.1":              i18n.English,
+			"es;q=0, en":               i18n.English,
+			"ES-es;q=0.9, en;q=bad":    i18n.Spanish,
+			"en-US, en;q=0.9, es;q=.8": i18n.English,
+		}
+
+		for header, locale := range headers {
+			header, locale := header, locale
+
+			It("Picks "+string(locale)+" for '"+header+"'", func() {
+				Expect(i18n.Negotiate(header)).To(Equal(locale))
+			})
+		}
+	})
+
+	Describe("FieldMessage", func() {
+		It("Uses the first key in the catalog", func() {
+			msg, ok := i18n.FieldMessage(i18n.Spanish, "title", "255", "max.string", "max")
+			Expect(ok).To(BeTrue())
+			Expect(msg).To(Equal("title debe tener como máximo 255 caracteres"))
+		})
+
+		It("Reports unknown keys", func() {
+			_, ok := i18n.FieldMessage(i18n.English, "title", "", "nope")
+			Expect(ok).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,16 @@
+package inventory_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/reporters"
+	. "github.com/onsi/gomega"
+)
+
+func TestInventory(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	junitReporter := reporters.NewJUnitReporter("reports/inventory.xml")
+	RunSpecsWithDefaultAndCustomReporters(t, "Inventory Suite", []Reporter{junitReporter})
+}
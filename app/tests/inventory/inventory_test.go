@@ -0,0 +1,226 @@
+package inventory_test
+
+import (
+	"errors"
+	"net/http"
+
+	"cuboid-challenge/app/api"
+	"cuboid-challenge/app/inventory"
+	. "cuboid-challenge/app/models"
+	"cuboid-challenge/app/packing"
+	"cuboid-challenge/app/store"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Inventory", func() {
+	var s store.Store
+	var service *inventory.Service
+	var bag, other *Bag
+
+	BeforeEach(func() {
+		s = store.NewMemory()
+		service = inventory.New(s)
+
+		bag = &Bag{Title: "A bag", Volume: 100, Cuboids: []Cuboid{{Width: 4, Height: 4, Depth: 4}}}
+		other = &Bag{Title: "Other", Volume: 70}
+		Expect(s.Bags().Create(bag)).To(Succeed())
+		Expect(s.Bags().Create(other)).To(Succeed())
+	})
+
+	Describe("AddCuboid", func() {
+		It("Stores a cuboid the bag has room for", func() {
+			cuboid := Cuboid{Width: 3, Height: 3, Depth: 3, BagID: bag.ID}
+			Expect(service.AddCuboid(&cuboid, false)).To(Succeed())
+			Expect(cuboid.ID).NotTo(BeZero())
+		})
+
+		It("Rejects a cuboid larger than what is left", func() {
+			cuboid := Cuboid{Width: 6, Height: 6, Depth: 2, BagID: bag.ID}
+			Expect(service.AddCuboid(&cuboid, false)).To(MatchError(inventory.ErrInsufficientCapacity))
+		})
+
+		It("Rejects a cuboid in a disabled bag", func() {
+			disabled := true
+			_, err := service.UpdateBag(bag.ID, nil, inventory.BagChange{Disabled: &disabled})
+			Expect(err).NotTo(HaveOccurred())
+
+			cuboid := Cuboid{Width: 1, Height: 1, Depth: 1, BagID: bag.ID}
+			Expect(service.AddCuboid(&cuboid, false)).To(MatchError(inventory.ErrBagIsDisabled))
+		})
+
+		It("Reports a missing bag", func() {
+			cuboid := Cuboid{Width: 1, Height: 1, Depth: 1, BagID: other.ID + 100}
+			Expect(service.AddCuboid(&cuboid, false)).To(MatchError(inventory.ErrBagNotFound))
+		})
+	})
+
+	Describe("AddCuboids", func() {
+		It("Stores none when one is rejected", func() {
+			items := []inventory.NewCuboid{
+				{Cuboid: &Cuboid{Width: 3, Height: 3, Depth: 3, BagID: other.ID}},
+				{Cuboid: &Cuboid{Width: 4, Height: 4, Depth: 4, BagID: other.ID}},
+			}
+			Expect(service.AddCuboids(items)).To(MatchError(inventory.ErrCuboidsRejected))
+			Expect(items[0].Err).NotTo(HaveOccurred())
+			Expect(items[1].Err).To(MatchError(inventory.ErrInsufficientCapacity))
+
+			stored, err := s.Bags().Get(other.ID, false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stored.Cuboids).To(BeEmpty())
+		})
+	})
+
+	Describe("UpdateCuboid", func() {
+		var cuboidID uint
+
+		BeforeEach(func() {
+			cuboidID = bag.Cuboids[0].ID
+		})
+
+		resize := func(c inventory.CuboidChange) func(Cuboid) (inventory.CuboidChange, error) {
+			return func(Cuboid) (inventory.CuboidChange, error) { return c, nil }
+		}
+
+		It("Resizes the cuboid within its bag", func() {
+			cuboid, err := service.UpdateCuboid(cuboidID, nil, resize(inventory.CuboidChange{Width: 5, Height: 5, Depth: 4}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cuboid.Width).To(BeEquivalentTo(5))
+			Expect(cuboid.Version).To(BeEquivalentTo(2))
+		})
+
+		It("Moves the cuboid when the other bag admits it", func() {
+			cuboid, err := service.UpdateCuboid(cuboidID, nil, resize(inventory.CuboidChange{Width: 3, Height: 3, Depth: 3, BagID: other.ID}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cuboid.BagID).To(Equal(other.ID))
+		})
+
+		It("Leaves the cuboid in place when the move fails", func() {
+			_, err := service.UpdateCuboid(cuboidID, nil, resize(inventory.CuboidChange{Width: 4, Height: 4, Depth: 5, BagID: other.ID}))
+			Expect(err).To(MatchError(inventory.ErrInsufficientCapacity))
+
+			stored, err := s.Cuboids().Get(cuboidID, false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stored.BagID).To(Equal(bag.ID))
+		})
+
+		It("Checks the precondition", func() {
+			never := func(uint) bool { return false }
+			_, err := service.UpdateCuboid(cuboidID, never, resize(inventory.CuboidChange{Width: 1, Height: 1, Depth: 1}))
+			Expect(err).To(MatchError(inventory.ErrVersionMismatch))
+		})
+
+		It("Passes the change error through", func() {
+			failure := errors.New("failure")
+			_, err := service.UpdateCuboid(cuboidID, nil, func(Cuboid) (inventory.CuboidChange, error) {
+				return inventory.CuboidChange{}, failure
+			})
+			Expect(err).To(Equal(failure))
+		})
+
+		It("Plans again when the change names another bag once the bags are locked", func() {
+			calls := 0
+			cuboid, err := service.UpdateCuboid(cuboidID, nil, func(Cuboid) (inventory.CuboidChange, error) {
+				calls++
+				change := inventory.CuboidChange{Width: 2, Height: 2, Depth: 2}
+				if calls == 1 {
+					change.BagID = other.ID
+				}
+
+				return change, nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cuboid.BagID).To(Equal(bag.ID))
+			Expect(calls).To(Equal(4))
+		})
+	})
+
+	Describe("UpdateCuboid when the bags keep changing", func() {
+		It("Gives up with a conflict", func() {
+			calls := 0
+			_, err := service.UpdateCuboid(bag.Cuboids[0].ID, nil, func(Cuboid) (inventory.CuboidChange, error) {
+				calls++
+				change := inventory.CuboidChange{Width: 2, Height: 2, Depth: 2}
+				// the plan names the other bag, the change once the bags are locked does not
+				if calls%2 == 1 {
+					change.BagID = other.ID
+				}
+
+				return change, nil
+			})
+			Expect(err).To(MatchError(inventory.ErrConcurrentUpdate))
+			Expect(calls).To(Equal(6))
+
+			t, _ := api.ProblemFor(err)
+			Expect(t.Status).To(Equal(http.StatusConflict))
+		})
+	})
+
+	Describe("CreateBag", func() {
+		It("Takes the volume of the dimensions when it is left out", func() {
+			created := Bag{Title: "Sized", Width: 2, Height: 3, Depth: 4}
+			Expect(service.CreateBag(&created)).To(Succeed())
+			Expect(created.ID).NotTo(BeZero())
+			Expect(created.Volume).To(BeEquivalentTo(24))
+		})
+
+		It("Keeps a volume that is set", func() {
+			created := Bag{Title: "Unsized", Volume: 30}
+			Expect(service.CreateBag(&created)).To(Succeed())
+			Expect(created.Volume).To(BeEquivalentTo(30))
+		})
+	})
+
+	Describe("UpdateBag", func() {
+		It("Keeps the volume above the payload", func() {
+			volume := uint(10)
+			_, err := service.UpdateBag(bag.ID, nil, inventory.BagChange{Volume: &volume})
+			Expect(err).To(MatchError(inventory.ErrVolumeBelowPayload))
+		})
+	})
+
+	Describe("DeleteBag", func() {
+		It("Refuses a bag with cuboids", func() {
+			Expect(service.DeleteBag(bag.ID, nil, inventory.DeleteRefuse, 0)).To(MatchError(inventory.ErrBagNotEmpty))
+		})
+
+		It("Moves the cuboids to the target bag", func() {
+			Expect(service.DeleteBag(bag.ID, nil, inventory.DeleteMove, other.ID)).To(Succeed())
+
+			stored, err := s.Bags().Get(other.ID, false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stored.Cuboids).To(HaveLen(1))
+		})
+
+		It("Brings the cascaded cuboids back on restore", func() {
+			Expect(service.DeleteBag(bag.ID, nil, inventory.DeleteCascade, 0)).To(Succeed())
+
+			restored, err := service.RestoreBag(bag.ID, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(restored.Cuboids).To(HaveLen(1))
+		})
+	})
+
+	Describe("CheckFit", func() {
+		It("Lists the bags that would take the cuboid", func() {
+			fit, err := service.CheckFit(bag.ID, Cuboid{Width: 5, Height: 5, Depth: 2}, false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fit.Err).To(MatchError(inventory.ErrInsufficientCapacity))
+			Expect(fit.Alternatives).To(HaveLen(1))
+			Expect(fit.Alternatives[0].Bag.ID).To(Equal(other.ID))
+			Expect(fit.Alternatives[0].Available).To(BeEquivalentTo(70))
+		})
+	})
+
+	Describe("Pack", func() {
+		It("Stores the assigned cuboids on commit", func() {
+			items := []packing.Dims{{Width: 3, Height: 3, Depth: 3}, {Width: 10, Height: 10, Depth: 10}}
+			assigned, unplaced, err := service.Pack(nil, items, packing.FirstFitDecreasing, false, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(assigned).To(HaveLen(1))
+			Expect(assigned[0].Cuboid.ID).NotTo(BeZero())
+			Expect(unplaced).To(Equal([]int{1}))
+		})
+	})
+})
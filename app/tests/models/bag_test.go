@@ -2,7 +2,9 @@ package models_test
 
 import (
 	"fmt"
+	"strings"
 
+	"cuboid-challenge/app/i18n"
 	"cuboid-challenge/app/models"
 	"cuboid-challenge/app/tests/testutils"
 
@@ -42,6 +44,61 @@ var _ = Describe("Bag", func() {
 			Expect(validationErr[0].Type).To(Equal("gt"))
 			Expect(validationErr[0].Param).To(Equal("0"))
 		})
+
+		It("Limits the Volume", func() {
+			over := uint64(models.MaxVolume) + 1
+			if uint64(^uint(0)) < over {
+				Skip("a uint can not go past MaxVolume")
+			}
+			bag.Volume = uint(over)
+			isValid, validationErr := models.Validate(bag)
+
+			Expect(isValid).To(Equal(false))
+			Expect(validationErr[0].Field).To(Equal("Volume"))
+			Expect(validationErr[0].Type).To(Equal("max"))
+			Expect(validationErr[0].Param).To(BeEquivalentTo(fmt.Sprint(uint64(models.MaxVolume))))
+		})
+
+		It("Requires every dimension once one is set", func() {
+			bag.Width = 10
+			isValid, validationErr := models.Validate(bag)
+
+			Expect(isValid).To(Equal(false))
+			Expect(validationErr[0].Field).To(Equal("Height"))
+			Expect(validationErr[1].Field).To(Equal("Depth"))
+		})
+
+		It("Requires the Volume to match the dimensions", func() {
+			bag.Width, bag.Height, bag.Depth = 1, 2, 3
+			isValid, validationErr := models.Validate(bag)
+
+			Expect(isValid).To(Equal(false))
+			Expect(validationErr[0].Field).To(Equal("Volume"))
+		})
+
+		Describe("Messages", func() {
+			It("Names the fields as in the JSON", func() {
+				bag.Volume = 0
+				_, validationErr := models.Validate(bag)
+
+				Expect(validationErr.Error()).To(Equal("volume must be greater than 0"))
+			})
+
+			It("Tells the length limit of strings", func() {
+				bag.Title = strings.Repeat("a", 256)
+				_, validationErr := models.Validate(bag)
+
+				Expect(validationErr.Error()).To(Equal("title must be at most 255 characters long"))
+				Expect(validationErr.Localize(i18n.Spanish)).To(Equal("title debe tener como máximo 255 caracteres"))
+			})
+
+			It("Translates the model rules", func() {
+				bag.Width, bag.Height, bag.Depth = 1, 2, 3
+				_, validationErr := models.Validate(bag)
+
+				Expect(validationErr.Localize(i18n.Spanish)).To(Equal("volume debe ser igual a width * height * depth"))
+			})
+		})
 	})
 
 	bags := map[string]struct {
@@ -48,6 +48,37 @@ var _ = Describe("Cuboid", func() {
 			cuboid.Depth = 0
 			validateGtThan0("Depth")
 		})
+
+		It("Limits the dimensions", func() {
+			cuboid.Width = models.MaxDimension + 1
+			isValid, validationErr := models.Validate(cuboid)
+
+			Expect(isValid).To(Equal(false))
+			Expect(validationErr.Error()).To(Equal("width must be at most 100000"))
+		})
+
+		It("Names the bag as in the JSON", func() {
+			Expect(models.JSONName("BagID")).To(Equal("bagId"))
+			Expect(models.JSONName("ID")).To(Equal("id"))
+		})
+	})
+
+	Describe("Placement", func() {
+		It("Exposes the position and orientation in JSON", func() {
+			cuboid := models.Cuboid{Width: 1, Height: 2, Depth: 3, X: 4, Y: 5, Z: 6, Orientation: "DHW"}
+			m, err := testutils.Serialize(&cuboid)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(m["placement"]).To(Equal(map[string]interface{}{
+				"x": 4.0, "y": 5.0, "z": 6.0, "orientation": "DHW",
+			}))
+		})
+
+		It("Turns the sizes to the orientation", func() {
+			cuboid := models.Cuboid{Width: 1, Height: 2, Depth: 3, Orientation: "DHW"}
+			p, ok := cuboid.Placement()
+			Expect(ok).To(BeTrue())
+			Expect([]uint{p.Size.Width, p.Size.Height, p.Size.Depth}).To(Equal([]uint{3, 2, 1}))
+		})
 	})
 
 	cuboids := map[string]struct {
@@ -74,6 +105,7 @@ var _ = Describe("Cuboid", func() {
 				Expect(m["depth"]).To(BeEquivalentTo(value.Cuboid.Depth))
 				Expect(m["volume"]).To(BeEquivalentTo(value.expectedVol))
 				Expect(m["bagId"]).To(BeEquivalentTo(value.Cuboid.BagID))
+				Expect(m["placement"]).To(BeNil())
 			})
 		})
 	}
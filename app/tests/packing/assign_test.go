@@ -0,0 +1,61 @@
+package packing_test
+
+import (
+	"cuboid-challenge/app/packing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Assign", func() {
+	var bins []packing.Bin
+	items := []packing.Dims{
+		{Width: 1, Height: 1, Depth: 2},
+		{Width: 2, Height: 2, Depth: 2},
+		{Width: 3, Height: 3, Depth: 3},
+	}
+
+	BeforeEach(func() {
+		bins = []packing.Bin{
+			{ID: 1, Volume: 30},
+			{ID: 2, Volume: 10},
+		}
+	})
+
+	Describe("First fit decreasing", func() {
+		It("Puts every item in the first bin with room for it", func() {
+			assigned, unplaced := packing.Assign(bins, items, packing.FirstFitDecreasing, false)
+			Expect(unplaced).To(BeEmpty())
+			Expect(assigned).To(HaveLen(3))
+			Expect(assigned[0].BinID).To(BeEquivalentTo(1))
+			Expect(assigned[1].BinID).To(BeEquivalentTo(2))
+			Expect(assigned[2].BinID).To(BeEquivalentTo(1))
+		})
+	})
+
+	Describe("Best fit decreasing", func() {
+		It("Puts items in the tightest bin", func() {
+			assigned, unplaced := packing.Assign(bins, items, packing.BestFitDecreasing, false)
+			Expect(unplaced).To(BeEmpty())
+			Expect(assigned[0].BinID).To(BeEquivalentTo(2))
+			Expect(assigned[1].BinID).To(BeEquivalentTo(2))
+			Expect(assigned[2].BinID).To(BeEquivalentTo(1))
+		})
+	})
+
+	It("Reports the items no bin can take", func() {
+		bins = []packing.Bin{{ID: 1, Volume: 8, Dims: packing.Dims{Width: 2, Height: 2, Depth: 2}}}
+		assigned, unplaced := packing.Assign(bins, items, packing.FirstFitDecreasing, false)
+		Expect(unplaced).To(Equal([]int{0, 2}))
+		Expect(assigned).To(HaveLen(1))
+		Expect(assigned[0].Item).To(Equal(1))
+		Expect(assigned[0].Placement.Orientation).To(Equal(packing.OrientationWHD))
+	})
+	It("Leaves out the items with a volume that overflows", func() {
+		huge := packing.Dims{Width: ^uint(0), Height: 2, Depth: 1}
+		assigned, unplaced := packing.Assign(bins, []packing.Dims{huge, items[0]}, packing.FirstFitDecreasing, false)
+		Expect(unplaced).To(Equal([]int{0}))
+		Expect(assigned).To(HaveLen(1))
+		Expect(assigned[0].Item).To(Equal(1))
+	})
+})
@@ -0,0 +1,16 @@
+package packing_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/reporters"
+	. "github.com/onsi/gomega"
+)
+
+func TestPacking(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	junitReporter := reporters.NewJUnitReporter("reports/packing.xml")
+	RunSpecsWithDefaultAndCustomReporters(t, "Packing Suite", []Reporter{junitReporter})
+}
@@ -0,0 +1,66 @@
+package packing_test
+
+import (
+	"cuboid-challenge/app/packing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Packing", func() {
+	bag := packing.Dims{Width: 10, Height: 10, Depth: 10}
+
+	Describe("Fit", func() {
+		It("Places the first box at the origin", func() {
+			p, ok := packing.Fit(bag, nil, packing.Dims{Width: 2, Height: 3, Depth: 4}, false)
+			Expect(ok).To(BeTrue())
+			Expect([]uint{p.X, p.Y, p.Z}).To(Equal([]uint{0, 0, 0}))
+			Expect(p.Orientation).To(Equal(packing.OrientationWHD))
+		})
+
+		It("Does not fit a long box even if the volume is enough", func() {
+			_, ok := packing.Fit(bag, nil, packing.Dims{Width: 1000, Height: 1, Depth: 1}, true)
+			Expect(ok).To(BeFalse())
+		})
+
+		It("Places a box next to the others without overlapping", func() {
+			placed, ok := packing.Pack(bag, []packing.Dims{{Width: 5, Height: 10, Depth: 10}}, false)
+			Expect(ok).To(BeTrue())
+
+			p, ok := packing.Fit(bag, placed, packing.Dims{Width: 5, Height: 10, Depth: 10}, false)
+			Expect(ok).To(BeTrue())
+			Expect(p.X).To(BeEquivalentTo(5))
+		})
+
+		Context("When the box only fits turned", func() {
+			item := packing.Dims{Width: 1, Height: 1, Depth: 20}
+			bag := packing.Dims{Width: 20, Height: 2, Depth: 2}
+
+			It("Does not fit without rotation", func() {
+				_, ok := packing.Fit(bag, nil, item, false)
+				Expect(ok).To(BeFalse())
+			})
+
+			It("Fits with rotation", func() {
+				p, ok := packing.Fit(bag, nil, item, true)
+				Expect(ok).To(BeTrue())
+				Expect(p.Size).To(Equal(packing.Dims{Width: 20, Height: 1, Depth: 1}))
+			})
+		})
+	})
+
+	Describe("Pack", func() {
+		It("Fills the bag with eight cubes", func() {
+			items := make([]packing.Dims, 8)
+			for i := range items {
+				items[i] = packing.Dims{Width: 5, Height: 5, Depth: 5}
+			}
+
+			_, ok := packing.Pack(bag, items, false)
+			Expect(ok).To(BeTrue())
+
+			_, ok = packing.Pack(bag, append(items, packing.Dims{Width: 1, Height: 1, Depth: 1}), false)
+			Expect(ok).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,31 @@
+package packing_test
+
+import (
+	"cuboid-challenge/app/packing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Volume", func() {
+	It("Multiplies the dimensions", func() {
+		Expect(packing.Dims{Width: 2, Height: 3, Depth: 4}.Volume()).To(BeEquivalentTo(24))
+	})
+
+	It("Detects a product that overflows", func() {
+		_, err := packing.Dims{Width: ^uint(0), Height: 2, Depth: 1}.Volume()
+		Expect(err).To(MatchError(packing.ErrVolumeOverflow))
+	})
+
+	It("Detects a sum that overflows", func() {
+		_, err := packing.AddVolume(^uint(0), 1)
+		Expect(err).To(MatchError(packing.ErrVolumeOverflow))
+	})
+
+	It("Detects a difference that underflows", func() {
+		_, err := packing.SubVolume(1, 2)
+		Expect(err).To(MatchError(packing.ErrVolumeUnderflow))
+
+		Expect(packing.SubVolume(2, 2)).To(BeZero())
+	})
+})
@@ -0,0 +1,16 @@
+package rpc_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/reporters"
+	. "github.com/onsi/gomega"
+)
+
+func TestRPC(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	junitReporter := reporters.NewJUnitReporter("reports/rpc.xml")
+	RunSpecsWithDefaultAndCustomReporters(t, "RPC Suite", []Reporter{junitReporter})
+}
@@ -0,0 +1,244 @@
+package rpc_test
+
+import (
+	"context"
+	"net"
+	"strconv"
+
+	"cuboid-challenge/app/rpc"
+	"cuboid-challenge/app/rpc/pb"
+	"cuboid-challenge/app/store"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+// reasonOf returns the problem code of a status error.
+func reasonOf(err error) string {
+	for _, d := range status.Convert(err).Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			return info.Reason
+		}
+	}
+
+	return ""
+}
+
+var _ = Describe("RPC", func() {
+	var server *grpc.Server
+	var conn *grpc.ClientConn
+	var client pb.InventoryClient
+	var ctx context.Context
+
+	BeforeEach(func() {
+		lis := bufconn.Listen(1 << 20)
+		server = rpc.NewServer(store.NewMemory())
+		go func() {
+			defer GinkgoRecover()
+			Expect(server.Serve(lis)).To(Succeed())
+		}()
+
+		var err error
+		conn, err = grpc.Dial("bufnet",
+			grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+			grpc.WithTransportCredentials(insecure.NewCredentials()))
+		Expect(err).NotTo(HaveOccurred())
+		client = pb.NewInventoryClient(conn)
+		ctx = context.Background()
+	})
+
+	AfterEach(func() {
+		Expect(conn.Close()).To(Succeed())
+		server.Stop()
+	})
+
+	createBag := func(title string, volume uint64) *pb.Bag {
+		bag, err := client.CreateBag(ctx, &pb.CreateBagRequest{Title: title, Volume: volume})
+		Expect(err).NotTo(HaveOccurred())
+
+		return bag
+	}
+
+	Describe("Bags", func() {
+		It("Creates and gets a bag", func() {
+			bag := createBag("A bag", 100)
+			Expect(bag.Id).NotTo(BeZero())
+			Expect(bag.AvailableVolume).To(BeEquivalentTo(100))
+
+			got, err := client.GetBag(ctx, &pb.GetRequest{Id: bag.Id})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(got.Title).To(Equal("A bag"))
+			Expect(got.Version).To(BeEquivalentTo(1))
+		})
+
+		It("Computes the volume from the dimensions", func() {
+			bag, err := client.CreateBag(ctx, &pb.CreateBagRequest{Title: "Box", Width: 2, Height: 3, Depth: 4})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bag.Volume).To(BeEquivalentTo(24))
+		})
+
+		It("Reports the validation errors by field", func() {
+			_, err := client.CreateBag(ctx, &pb.CreateBagRequest{Title: "", Volume: 10})
+			Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+			Expect(reasonOf(err)).To(Equal("validation_failed"))
+
+			var fields []string
+			for _, d := range status.Convert(err).Details() {
+				if badRequest, ok := d.(*errdetails.BadRequest); ok {
+					for _, v := range badRequest.FieldViolations {
+						fields = append(fields, v.Field)
+					}
+				}
+			}
+			Expect(fields).To(ConsistOf("title"))
+		})
+
+		It("Reports a missing bag", func() {
+			_, err := client.GetBag(ctx, &pb.GetRequest{Id: 1000})
+			Expect(status.Code(err)).To(Equal(codes.NotFound))
+			Expect(reasonOf(err)).To(Equal("not_found"))
+		})
+
+		It("Lists the bags a page at a time", func() {
+			createBag("a", 10)
+			createBag("b", 30)
+			createBag("c", 20)
+
+			page, err := client.ListBags(ctx, &pb.ListRequest{Limit: 2, Sort: "-volume"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(page.Total).To(BeEquivalentTo(3))
+			Expect(page.Bags).To(HaveLen(2))
+			Expect(page.Bags[0].Title).To(Equal("b"))
+			Expect(page.NextCursor).NotTo(BeEmpty())
+
+			page, err = client.ListBags(ctx, &pb.ListRequest{Limit: 2, Sort: "-volume", Cursor: page.NextCursor})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(page.Bags).To(HaveLen(1))
+			Expect(page.Bags[0].Title).To(Equal("a"))
+			Expect(page.NextCursor).To(BeEmpty())
+		})
+
+		It("Filters the bags like the REST list", func() {
+			createBag("a", 10)
+			createBag("b", 30)
+
+			page, err := client.ListBags(ctx, &pb.ListRequest{Filters: map[string]string{"minVolume": "20"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(page.Bags).To(HaveLen(1))
+			Expect(page.Bags[0].Title).To(Equal("b"))
+
+			_, err = client.ListBags(ctx, &pb.ListRequest{Sort: "color"})
+			Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+			Expect(reasonOf(err)).To(Equal("invalid_sort"))
+		})
+
+		It("Updates the fields that are set", func() {
+			bag := createBag("A bag", 100)
+
+			updated, err := client.UpdateBag(ctx, &pb.UpdateBagRequest{Id: bag.Id, Disabled: proto.Bool(true)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updated.Disabled).To(BeTrue())
+			Expect(updated.Title).To(Equal("A bag"))
+			Expect(updated.Version).To(BeEquivalentTo(2))
+		})
+
+		It("Checks the expected version", func() {
+			bag := createBag("A bag", 100)
+
+			_, err := client.UpdateBag(ctx, &pb.UpdateBagRequest{Id: bag.Id, Title: proto.String("x"), ExpectedVersion: 5})
+			Expect(status.Code(err)).To(Equal(codes.Aborted))
+			Expect(reasonOf(err)).To(Equal("version_mismatch"))
+		})
+
+		It("Refuses to delete a bag with cuboids", func() {
+			bag := createBag("A bag", 100)
+			_, err := client.CreateCuboid(ctx, &pb.CreateCuboidRequest{Width: 1, Height: 1, Depth: 1, BagId: bag.Id})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = client.DeleteBag(ctx, &pb.DeleteBagRequest{Id: bag.Id})
+			Expect(status.Code(err)).To(Equal(codes.FailedPrecondition))
+			Expect(reasonOf(err)).To(Equal("bag_not_empty"))
+
+			_, err = client.DeleteBag(ctx, &pb.DeleteBagRequest{Id: bag.Id, Cuboids: "cascade"})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = client.GetBag(ctx, &pb.GetRequest{Id: bag.Id})
+			Expect(status.Code(err)).To(Equal(codes.NotFound))
+		})
+
+		It("Tells where a cuboid would fit", func() {
+			small := createBag("Small", 10)
+			large := createBag("Large", 100)
+
+			fit, err := client.CheckFit(ctx, &pb.CheckFitRequest{BagId: small.Id, Width: 3, Height: 3, Depth: 3})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fit.Fits).To(BeFalse())
+			Expect(fit.Reason).To(Equal("Insufficient capacity in bag"))
+			Expect(fit.Alternatives).To(HaveLen(1))
+			Expect(fit.Alternatives[0].Id).To(Equal(large.Id))
+		})
+	})
+
+	Describe("Cuboids", func() {
+		var bag *pb.Bag
+
+		BeforeEach(func() {
+			bag = createBag("A bag", 100)
+		})
+
+		It("Creates a cuboid the bag has room for", func() {
+			cuboid, err := client.CreateCuboid(ctx, &pb.CreateCuboidRequest{Width: 2, Height: 3, Depth: 4, BagId: bag.Id})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cuboid.Volume).To(BeEquivalentTo(24))
+
+			got, err := client.GetBag(ctx, &pb.GetRequest{Id: bag.Id})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(got.PayloadVolume).To(BeEquivalentTo(24))
+			Expect(got.Cuboids).To(HaveLen(1))
+		})
+
+		It("Rejects a cuboid larger than what is left", func() {
+			_, err := client.CreateCuboid(ctx, &pb.CreateCuboidRequest{Width: 5, Height: 5, Depth: 5, BagId: bag.Id})
+			Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+			Expect(reasonOf(err)).To(Equal("insufficient_capacity"))
+		})
+
+		It("Reports the missing bag of a cuboid", func() {
+			_, err := client.CreateCuboid(ctx, &pb.CreateCuboidRequest{Width: 1, Height: 1, Depth: 1, BagId: 1000})
+			Expect(status.Code(err)).To(Equal(codes.NotFound))
+			Expect(reasonOf(err)).To(Equal("bag_not_found"))
+		})
+
+		It("Updates, lists and deletes a cuboid", func() {
+			cuboid, err := client.CreateCuboid(ctx, &pb.CreateCuboidRequest{Width: 1, Height: 1, Depth: 1, BagId: bag.Id})
+			Expect(err).NotTo(HaveOccurred())
+
+			updated, err := client.UpdateCuboid(ctx, &pb.UpdateCuboidRequest{
+				Id: cuboid.Id, Width: 2, Height: 2, Depth: 2, ExpectedVersion: cuboid.Version,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updated.Volume).To(BeEquivalentTo(8))
+
+			page, err := client.ListCuboids(ctx, &pb.ListRequest{Filters: map[string]string{"bagId": strconv.FormatUint(bag.Id, 10)}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(page.Cuboids).To(HaveLen(1))
+
+			_, err = client.DeleteCuboid(ctx, &pb.DeleteCuboidRequest{Id: cuboid.Id, ExpectedVersion: cuboid.Version})
+			Expect(status.Code(err)).To(Equal(codes.Aborted))
+
+			_, err = client.DeleteCuboid(ctx, &pb.DeleteCuboidRequest{Id: cuboid.Id})
+			Expect(err).NotTo(HaveOccurred())
+
+			deleted, err := client.GetCuboid(ctx, &pb.GetRequest{Id: cuboid.Id, IncludeDeleted: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deleted.Deleted).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,120 @@
+package store_test
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"cuboid-challenge/app/db"
+	. "cuboid-challenge/app/models"
+	"cuboid-challenge/app/store"
+	"cuboid-challenge/app/tests/testutils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// The specs run the requests on the Gorm store, whose locks keep the bags from being overfilled.
+var _ = Describe("Concurrent cuboid writes", func() {
+	testutils.LoadEnv()
+	testutils.ConnectDB()
+	testutils.ClearDB()
+
+	AfterEach(func() {
+		testutils.ClearDB()
+	})
+
+	const requests = 40
+	var s store.Store
+	var bag *Bag
+
+	BeforeEach(func() {
+		s = store.NewGorm(db.CONN)
+		bag = &Bag{Title: "A bag", Volume: 10}
+		testutils.AddRecords(s, bag)
+	})
+
+	hammer := func(request func(i int) int) map[int]int {
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		codes := map[int]int{}
+
+		for i := 0; i < requests; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer GinkgoRecover()
+				defer wg.Done()
+
+				code := request(i)
+				mu.Lock()
+				codes[code]++
+				mu.Unlock()
+			}(i)
+		}
+		wg.Wait()
+
+		return codes
+	}
+
+	payloadVolume := func() uint {
+		stored, err := s.Bags().Get(bag.ID, false)
+		Expect(err).NotTo(HaveOccurred())
+
+		volume, err := stored.PayloadVolume()
+		Expect(err).NotTo(HaveOccurred())
+
+		return volume
+	}
+
+	It("Never overfills the bag with parallel creates", func() {
+		codes := hammer(func(int) int {
+			body := fmt.Sprintf(`{"width": 1, "height": 1, "depth": 1, "bagId": %d}`, bag.ID)
+
+			return testutils.MockRequest(s, http.MethodPost, "/cuboids", &body).Code
+		})
+
+		Expect(codes[http.StatusCreated]).To(Equal(10))
+		Expect(codes[http.StatusBadRequest]).To(Equal(requests - 10))
+		Expect(payloadVolume()).To(BeEquivalentTo(10))
+	})
+
+	It("Never overfills the bag with parallel resizes", func() {
+		cuboids := make([]Cuboid, 5)
+		for i := range cuboids {
+			cuboids[i] = Cuboid{Width: 1, Height: 1, Depth: 1, BagID: bag.ID}
+			testutils.AddRecords(s, &cuboids[i])
+		}
+
+		hammer(func(i int) int {
+			body := `{"width": 1, "height": 1, "depth": 3}`
+			path := fmt.Sprintf("/cuboids/%d", cuboids[i%len(cuboids)].ID)
+
+			return testutils.MockRequest(s, http.MethodPut, path, &body).Code
+		})
+
+		Expect(payloadVolume()).To(BeNumerically("<=", 10))
+	})
+
+	It("Moves cuboids both ways between two bags without failing", func() {
+		other := &Bag{Title: "Other", Volume: 10}
+		testutils.AddRecords(s, other)
+
+		bags := []*Bag{bag, other}
+		cuboids := make([]Cuboid, 2)
+		for i := range cuboids {
+			cuboids[i] = Cuboid{Width: 1, Height: 1, Depth: 1, BagID: bags[i].ID}
+			testutils.AddRecords(s, &cuboids[i])
+		}
+
+		codes := hammer(func(i int) int {
+			// both cuboids go back and forth between the bags, so moves in opposite directions overlap
+			body := fmt.Sprintf(`{"width": 1, "height": 1, "depth": 1, "bagId": %d}`, bags[(i/2)%2].ID)
+			path := fmt.Sprintf("/cuboids/%d", cuboids[i%2].ID)
+
+			return testutils.MockRequest(s, http.MethodPut, path, &body).Code
+		})
+
+		Expect(codes[http.StatusInternalServerError]).To(BeZero())
+		Expect(codes[http.StatusOK]).To(Equal(requests))
+	})
+})
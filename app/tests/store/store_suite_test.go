@@ -0,0 +1,16 @@
+package store_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/reporters"
+	. "github.com/onsi/gomega"
+)
+
+func TestStore(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	junitReporter := reporters.NewJUnitReporter("reports/store.xml")
+	RunSpecsWithDefaultAndCustomReporters(t, "Store Suite", []Reporter{junitReporter})
+}
@@ -0,0 +1,288 @@
+package store_test
+
+import (
+	"errors"
+	"time"
+
+	"cuboid-challenge/app/db"
+	. "cuboid-challenge/app/models"
+	"cuboid-challenge/app/store"
+	"cuboid-challenge/app/tests/testutils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// describeStore runs the same specs on every implementation, they must behave alike.
+func describeStore(name string, newStore func() store.Store) {
+	Describe(name, func() {
+		var s store.Store
+		var bags []*Bag
+
+		BeforeEach(func() {
+			s = newStore()
+			bags = []*Bag{
+				{Title: "a", Volume: 10, Cuboids: []Cuboid{{Width: 2, Height: 2, Depth: 2}}},
+				{Title: "b", Volume: 20, Cuboids: []Cuboid{{Width: 1, Height: 1, Depth: 1}}},
+				{Title: "c", Volume: 5, Disabled: true},
+			}
+			for _, bag := range bags {
+				Expect(s.Bags().Create(bag)).To(Succeed())
+			}
+		})
+
+		Describe("Bags", func() {
+			It("Gets a bag with its cuboids", func() {
+				bag, err := s.Bags().Get(bags[0].ID, false)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(bag.Title).To(Equal("a"))
+				Expect(bag.Version).To(BeEquivalentTo(1))
+				Expect(bag.Cuboids).To(HaveLen(1))
+			})
+
+			It("Reports a missing bag", func() {
+				_, err := s.Bags().Get(bags[2].ID+100, false)
+				Expect(err).To(Equal(store.ErrNotFound))
+			})
+
+			It("Rejects an invalid bag", func() {
+				Expect(s.Bags().Create(&Bag{Title: "", Volume: 1})).NotTo(Succeed())
+			})
+
+			It("Updates the given columns only", func() {
+				bag := *bags[1]
+				bag.Title, bag.Volume = "renamed", 1_000
+				Expect(s.Bags().Update(&bag, "title")).To(Succeed())
+
+				stored, err := s.Bags().Get(bag.ID, false)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stored.Title).To(Equal("renamed"))
+				Expect(stored.Volume).To(BeEquivalentTo(20))
+			})
+
+			It("Returns the enabled bags in order", func() {
+				enabled, err := s.Bags().Enabled(nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(enabled).To(HaveLen(2))
+				Expect(enabled[0].ID).To(Equal(bags[0].ID))
+				Expect(enabled[1].ID).To(Equal(bags[1].ID))
+			})
+
+			It("Tells whether a bag exists", func() {
+				Expect(s.Bags().Exists(bags[0].ID, false)).To(BeTrue())
+				Expect(s.Bags().Exists(bags[2].ID+100, false)).To(BeFalse())
+
+				bag := *bags[0]
+				bag.DeletedAt.Time, bag.DeletedAt.Valid = s.Now(), true
+				Expect(s.Bags().Update(&bag, "deleted_at")).To(Succeed())
+				Expect(s.Bags().Exists(bag.ID, false)).To(BeFalse())
+				Expect(s.Bags().Exists(bag.ID, true)).To(BeTrue())
+			})
+
+			It("Counts the existing bags", func() {
+				count, err := s.Bags().Count([]uint{bags[0].ID, bags[2].ID, bags[2].ID + 100})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(count).To(BeEquivalentTo(2))
+			})
+		})
+
+		Describe("List", func() {
+			It("Sorts, filters and pages the bags", func() {
+				q := store.ListQuery{Limit: 1, Sort: "availableVolume", Desc: true, Filters: map[string]interface{}{"disabled": false}}
+
+				page, total, next, err := s.Bags().List(q)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(total).To(BeEquivalentTo(2))
+				Expect(page).To(HaveLen(1))
+				Expect(page[0].Title).To(Equal("b"))
+				Expect(page[0].Cuboids).To(HaveLen(1))
+				Expect(next).NotTo(BeNil())
+
+				q.After = next
+				page, _, next, err = s.Bags().List(q)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(page).To(HaveLen(1))
+				Expect(page[0].Title).To(Equal("a"))
+				Expect(next).To(BeNil())
+			})
+
+			It("Leaves the cuboids out when asked", func() {
+				page, _, _, err := s.Bags().List(store.ListQuery{Limit: 10, Sort: "id", WithoutCuboids: true})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(page).To(HaveLen(3))
+				Expect(page[0].Cuboids).To(BeEmpty())
+			})
+
+			It("Reads the cuboids of several bags at once", func() {
+				cuboids, err := s.Cuboids().InBags([]uint{bags[0].ID, bags[1].ID, bags[2].ID}, false)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cuboids).To(HaveLen(2))
+				Expect(cuboids[0].BagID).To(Equal(bags[0].ID))
+				Expect(cuboids[1].BagID).To(Equal(bags[1].ID))
+			})
+
+			It("Lists the cuboids of a bag", func() {
+				page, total, _, err := s.Cuboids().List(store.ListQuery{Limit: 10, Sort: "id", BagID: bags[1].ID})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(total).To(BeEquivalentTo(1))
+				Expect(page[0].BagID).To(Equal(bags[1].ID))
+			})
+
+			It("Filters the cuboids on their volume", func() {
+				q := store.ListQuery{Limit: 10, Sort: "volume", Filters: map[string]interface{}{"minVolume": uint64(2)}}
+				page, _, _, err := s.Cuboids().List(q)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(page).To(HaveLen(1))
+				Expect(page[0].BagID).To(Equal(bags[0].ID))
+			})
+		})
+
+		Describe("Soft delete", func() {
+			var cuboidID uint
+
+			BeforeEach(func() {
+				cuboidID = bags[0].Cuboids[0].ID
+			})
+
+			It("Deletes a cuboid at its version only", func() {
+				cuboid, err := s.Cuboids().Get(cuboidID, false)
+				Expect(err).NotTo(HaveOccurred())
+
+				deleted, err := s.Cuboids().Delete(&cuboid, cuboid.Version+1)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(deleted).To(BeFalse())
+
+				deleted, err = s.Cuboids().Delete(&cuboid, cuboid.Version)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(deleted).To(BeTrue())
+
+				_, err = s.Cuboids().Get(cuboidID, false)
+				Expect(err).To(Equal(store.ErrNotFound))
+
+				stored, err := s.Cuboids().Get(cuboidID, true)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stored.DeletedAt.Valid).To(BeTrue())
+			})
+
+			It("Restores the cuboids deleted with their bag", func() {
+				at := s.Now()
+				Expect(s.Cuboids().DeleteInBag(bags[0].ID, at)).To(Succeed())
+
+				bag, err := s.Bags().Get(bags[0].ID, false)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(bag.Cuboids).To(BeEmpty())
+
+				Expect(s.Cuboids().RestoreInBag(bags[0].ID, at)).To(Succeed())
+				cuboid, err := s.Cuboids().Get(cuboidID, false)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cuboid.Version).To(BeEquivalentTo(2))
+			})
+
+			It("Purges what was deleted before the time", func() {
+				Expect(s.Cuboids().DeleteMany([]uint{cuboidID})).To(Succeed())
+
+				purged, err := s.Cuboids().Purge(s.Now().Add(1))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(purged).To(BeEquivalentTo(1))
+
+				_, err = s.Cuboids().Get(cuboidID, true)
+				Expect(err).To(Equal(store.ErrNotFound))
+			})
+
+			It("Purges only what was deleted at least the given age ago", func() {
+				Expect(s.Cuboids().DeleteInBag(bags[0].ID, s.Now().Add(-2*time.Hour))).To(Succeed())
+				Expect(s.Cuboids().DeleteInBag(bags[1].ID, s.Now())).To(Succeed())
+
+				purged, err := store.Purge(s, time.Hour, 24*time.Hour)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(purged.Cuboids).To(BeEquivalentTo(1))
+
+				_, err = s.Cuboids().Get(cuboidID, true)
+				Expect(err).To(Equal(store.ErrNotFound))
+
+				recent, err := s.Cuboids().InBags([]uint{bags[1].ID}, true)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(recent).To(HaveLen(1))
+			})
+		})
+
+		Describe("Transaction", func() {
+			It("Rolls back when the function fails", func() {
+				failure := errors.New("failure")
+				err := s.Transaction(func(tx store.Store) error {
+					bag := *bags[2]
+					bag.Title = "changed"
+					Expect(tx.Bags().Update(&bag, "title")).To(Succeed())
+					Expect(tx.Cuboids().Create(&Cuboid{Width: 1, Height: 1, Depth: 1, BagID: bags[1].ID})).To(Succeed())
+
+					return failure
+				})
+				Expect(err).To(Equal(failure))
+
+				bag, err := s.Bags().Get(bags[2].ID, false)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(bag.Title).To(Equal("c"))
+
+				bag, err = s.Bags().Get(bags[1].ID, false)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(bag.Cuboids).To(HaveLen(1))
+			})
+		})
+
+		Describe("Idempotency keys", func() {
+			It("Claims a key once", func() {
+				record := IdempotencyKey{Key: "k", RequestHash: "h", CreatedAt: s.Now()}
+				claimed, err := s.IdempotencyKeys().Claim(&record)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(claimed).To(BeTrue())
+
+				again := IdempotencyKey{Key: "k", RequestHash: "other", CreatedAt: s.Now()}
+				claimed, err = s.IdempotencyKeys().Claim(&again)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(claimed).To(BeFalse())
+
+				record.Status, record.ETag, record.ContentType, record.Body = 201, `"1"`, "application/json", []byte("{}")
+				Expect(s.IdempotencyKeys().Finish(&record)).To(Succeed())
+				stored, err := s.IdempotencyKeys().Get("k")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stored.RequestHash).To(Equal("h"))
+				Expect(stored.Status).To(Equal(201))
+				Expect(stored.ContentType).To(Equal("application/json"))
+			})
+
+			It("Resets the whole response when a key is replaced", func() {
+				record := IdempotencyKey{Key: "k", RequestHash: "h", CreatedAt: s.Now()}
+				_, err := s.IdempotencyKeys().Claim(&record)
+				Expect(err).NotTo(HaveOccurred())
+				record.Status, record.ETag, record.ContentType, record.Body = 201, `"1"`, "application/json", []byte("{}")
+				Expect(s.IdempotencyKeys().Finish(&record)).To(Succeed())
+
+				replacing := IdempotencyKey{Key: "k", RequestHash: "other", CreatedAt: s.Now()}
+				replaced, err := s.IdempotencyKeys().Replace(record.CreatedAt, &replacing)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(replaced).To(BeTrue())
+
+				stored, err := s.IdempotencyKeys().Get("k")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stored.RequestHash).To(Equal("other"))
+				Expect(stored.Status).To(BeZero())
+				Expect(stored.ETag).To(BeEmpty())
+				Expect(stored.ContentType).To(BeEmpty())
+				Expect(stored.Body).To(BeEmpty())
+			})
+		})
+	})
+}
+
+var _ = Describe("Store", func() {
+	testutils.LoadEnv()
+	testutils.ConnectDB()
+	testutils.ClearDB()
+
+	AfterEach(func() {
+		testutils.ClearDB()
+	})
+
+	describeStore("Gorm", func() store.Store { return store.NewGorm(db.CONN) })
+	describeStore("Memory", func() store.Store { return store.NewMemory() })
+})
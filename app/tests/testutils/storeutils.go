@@ -0,0 +1,69 @@
+package testutils
+
+import (
+	"fmt"
+
+	"cuboid-challenge/app/models"
+	"cuboid-challenge/app/store"
+)
+
+// AddRecords stores the bags, with their cuboids, and the cuboids in s.
+func AddRecords(s store.Store, records ...interface{}) {
+	for _, record := range records {
+		var err error
+		switch r := record.(type) {
+		case *models.Bag:
+			err = s.Bags().Create(r)
+		case *models.Cuboid:
+			err = s.Cuboids().Create(r)
+		default:
+			err = fmt.Errorf("unknown record %T", record)
+		}
+
+		if err != nil {
+			panic(fmt.Errorf("failed to AddRecords %w", err))
+		}
+	}
+}
+
+// UpdateRecord writes the given columns of the bag or the cuboid to s.
+func UpdateRecord(s store.Store, record interface{}, columns ...string) {
+	var err error
+	switch r := record.(type) {
+	case *models.Bag:
+		err = s.Bags().Update(r, columns...)
+	case *models.Cuboid:
+		err = s.Cuboids().Update(r, columns...)
+	default:
+		err = fmt.Errorf("unknown record %T", record)
+	}
+
+	if err != nil {
+		panic(fmt.Errorf("failed to UpdateRecord. %w", err))
+	}
+}
+
+// FindRecord reads the bag or the cuboid with the id from s, and reports whether it is there.
+func FindRecord(s store.Store, record interface{}, id uint) bool {
+	var err error
+	switch r := record.(type) {
+	case *models.Bag:
+		*r, err = s.Bags().Get(id, false)
+	case *models.Cuboid:
+		*r, err = s.Cuboids().Get(id, false)
+	default:
+		err = fmt.Errorf("unknown record %T", record)
+	}
+
+	return err == nil
+}
+
+// CountCuboids returns how many cuboids s holds, only those of the bag when bagID is not 0.
+func CountCuboids(s store.Store, bagID uint) int64 {
+	_, count, _, err := s.Cuboids().List(store.ListQuery{Limit: 1, Sort: "id", BagID: bagID})
+	if err != nil {
+		panic(fmt.Errorf("failed to CountCuboids. %w", err))
+	}
+
+	return count
+}
@@ -10,10 +10,21 @@ import (
 	"strings"
 
 	"cuboid-challenge/app/router"
+	"cuboid-challenge/app/store"
 )
 
-func MockRequest(method, path string, bodyStr *string) *httptest.ResponseRecorder {
-	r := router.Setup()
+// MockRequest sends the request to the router set up on s, usually a store.NewMemory of the spec.
+func MockRequest(s store.Store, method, path string, bodyStr *string) *httptest.ResponseRecorder {
+	return MockRequestWithHeaders(s, method, path, bodyStr, nil)
+}
+
+func MockRequestWithHeaders(s store.Store, method, path string, bodyStr *string, headers map[string]string) *httptest.ResponseRecorder {
+	return ServeRequest(router.Setup(s), method, path, bodyStr, headers)
+}
+
+// ServeRequest sends the request to the given router, like one set up on a store of its own with
+// store.NewMemory, so that the tests using it can run in parallel.
+func ServeRequest(r http.Handler, method, path string, bodyStr *string, headers map[string]string) *httptest.ResponseRecorder {
 	w := httptest.NewRecorder()
 
 	var body io.Reader
@@ -25,6 +36,9 @@ func MockRequest(method, path string, bodyStr *string) *httptest.ResponseRecorde
 
 	ctx := context.Background()
 	req, _ := http.NewRequestWithContext(ctx, method, path, body)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
 	r.ServeHTTP(w, req)
 
 	return w
@@ -0,0 +1,16 @@
+package units_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/reporters"
+	. "github.com/onsi/gomega"
+)
+
+func TestUnits(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	junitReporter := reporters.NewJUnitReporter("reports/units.xml")
+	RunSpecsWithDefaultAndCustomReporters(t, "Units Suite", []Reporter{junitReporter})
+}
@@ -0,0 +1,107 @@
+package units_test
+
+import (
+	"encoding/json"
+
+	"cuboid-challenge/app/units"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Units", func() {
+	value := func(s string) units.Value {
+		v, err := units.ParseValue(s)
+		Expect(err).ToNot(HaveOccurred())
+
+		return v
+	}
+
+	Describe("Parse", func() {
+		It("Reads the unit names in any case", func() {
+			Expect(units.Parse("CM")).To(Equal(units.Centimetre))
+			Expect(units.Parse("in")).To(Equal(units.Inch))
+		})
+
+		It("Defaults to the canonical unit", func() {
+			Expect(units.Parse("")).To(Equal(units.Canonical))
+		})
+
+		It("Rejects unknown units", func() {
+			_, err := units.Parse("ft")
+			Expect(err).To(MatchError(ContainSubstring("unknown unit")))
+		})
+	})
+
+	Describe("ParseValue", func() {
+		It("Rejects negative numbers and fractions", func() {
+			for _, s := range []string{"-1", "1/2", "x"} {
+				_, err := units.ParseValue(s)
+				Expect(err).To(HaveOccurred())
+			}
+		})
+	})
+
+	Describe("Length", func() {
+		It("Converts to the canonical unit", func() {
+			Expect(units.Centimetre.Length(value("2.5"))).To(BeEquivalentTo(25))
+			Expect(units.Inch.Length(value("10"))).To(BeEquivalentTo(254))
+			Expect(units.Millimetre.Length(value("1e3"))).To(BeEquivalentTo(1000))
+		})
+
+		It("Rejects lengths that lose precision", func() {
+			_, err := units.Centimetre.Length(value("2.55"))
+			Expect(err).To(MatchError(units.ErrInexact))
+
+			_, err = units.Inch.Length(value("1"))
+			Expect(err).To(MatchError(units.ErrInexact))
+		})
+
+		It("Rejects lengths that do not fit a uint", func() {
+			_, err := units.Millimetre.Length(value("1e30"))
+			Expect(err).To(MatchError(units.ErrOutOfRange))
+		})
+	})
+
+	Describe("Volume", func() {
+		It("Converts with the cube of the unit", func() {
+			Expect(units.Centimetre.Volume(value("2"))).To(BeEquivalentTo(2000))
+			Expect(units.Inch.Volume(value("125"))).To(BeEquivalentTo(2048383))
+		})
+	})
+
+	Describe("Render", func() {
+		It("Renders whole numbers exactly", func() {
+			Expect(units.Centimetre.RenderLength(25)).To(Equal(json.Number("2.5")))
+			Expect(units.Centimetre.RenderVolume(2000)).To(Equal(json.Number("2")))
+			Expect(units.Inch.RenderLength(254)).To(Equal(json.Number("10")))
+		})
+
+		It("Leaves the canonical unit as it is", func() {
+			Expect(units.Millimetre.RenderLength(7)).To(Equal(json.Number("7")))
+		})
+	})
+
+	Describe("JSON", func() {
+		It("Keeps the digits of the values", func() {
+			var in struct {
+				Width units.Value
+				Unit  units.Unit
+			}
+			Expect(json.Unmarshal([]byte(`{"width": 2.50, "unit": "cm"}`), &in)).To(Succeed())
+			Expect(in.Unit).To(Equal(units.Centimetre))
+
+			b, err := json.Marshal(in.Width)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(b)).To(Equal("2.50"))
+		})
+
+		It("Fails to convert values that are not numbers", func() {
+			var in struct{ Width units.Value }
+			Expect(json.Unmarshal([]byte(`{"width": "2"}`), &in)).To(Succeed())
+
+			_, err := units.Millimetre.Length(in.Width)
+			Expect(err).To(MatchError(units.ErrNotANumber))
+		})
+	})
+})
@@ -0,0 +1,218 @@
+// Package units converts the sizes the API takes and renders between units of length.
+// Sizes are stored in the canonical unit, volumes in its cube.
+package units
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Unit is a unit of length.
+type Unit string
+
+const (
+	Millimetre Unit = "mm"
+	Centimetre Unit = "cm"
+	Inch       Unit = "in"
+)
+
+// Canonical is the unit sizes are stored in, and the one of requests and responses that name none.
+const Canonical = Millimetre
+
+var (
+	ErrUnknownUnit = errors.New("unknown unit")
+	ErrNotANumber  = errors.New("not a non-negative number")
+	ErrInexact     = errors.New("not a whole number of the canonical unit")
+	ErrOutOfRange  = errors.New("out of range")
+)
+
+// millimetres tells how many millimetres make one of each unit.
+var millimetres = map[Unit]*big.Rat{
+	Millimetre: big.NewRat(1, 1),
+	Centimetre: big.NewRat(10, 1),
+	Inch:       big.NewRat(127, 5),
+}
+
+// Units lists the known units.
+func Units() []Unit {
+	return []Unit{Millimetre, Centimetre, Inch}
+}
+
+// Parse returns the unit named s, case insensitive. An empty s is the canonical unit.
+func Parse(s string) (Unit, error) {
+	if s == "" {
+		return Canonical, nil
+	}
+
+	u := Unit(strings.ToLower(s))
+	if _, ok := millimetres[u]; !ok {
+		return "", fmt.Errorf("%w %q, it must be one of mm, cm or in", ErrUnknownUnit, s)
+	}
+
+	return u, nil
+}
+
+// UnmarshalJSON reads a unit name, see Parse. Any other kind of value is an unknown unit.
+func (u *Unit) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("%w, got a %s, it must be one of mm, cm or in", ErrUnknownUnit, jsonValueKind(b))
+	}
+
+	parsed, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*u = parsed
+
+	return nil
+}
+
+func (u Unit) factor(power int) *big.Rat {
+	f := new(big.Rat).SetInt64(1)
+	for i := 0; i < power; i++ {
+		f.Mul(f, millimetres[u])
+	}
+
+	return f
+}
+
+// Value is a non-negative decimal number as the client sent it, in the unit of the request.
+// The zero Value is 0.
+type Value struct {
+	text string
+	rat  *big.Rat
+
+	// bad describes the JSON value when it is not a non-negative number
+	bad string
+}
+
+// NewValue returns the Value of a whole number.
+func NewValue(n uint) Value {
+	text := strconv.FormatUint(uint64(n), 10)
+	rat, _ := new(big.Rat).SetString(text)
+
+	return Value{text: text, rat: rat}
+}
+
+// ParseValue reads a decimal number, like 12, 2.5 or 1e3.
+func ParseValue(s string) (Value, error) {
+	rat, ok := new(big.Rat).SetString(s)
+	if !ok || rat.Sign() < 0 || strings.Contains(s, "/") {
+		return Value{}, fmt.Errorf("%q is not a non-negative number", s)
+	}
+
+	return Value{text: s, rat: rat}, nil
+}
+
+// UnmarshalJSON reads a JSON number, keeping its digits. Negative numbers and other kinds of values
+// are kept as bad values that fail to convert with ErrNotANumber, so the caller can name the field,
+// which encoding/json does not tell for the errors of an Unmarshaler.
+func (v *Value) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+
+	parsed, err := ParseValue(string(b))
+	if err != nil {
+		*v = Value{bad: jsonValueKind(b)}
+
+		return nil
+	}
+	*v = parsed
+
+	return nil
+}
+
+// MarshalJSON writes the number as it was sent.
+func (v Value) MarshalJSON() ([]byte, error) {
+	if v.rat == nil {
+		return []byte("0"), nil
+	}
+
+	return []byte(v.text), nil
+}
+
+// Length converts a length in u to the canonical unit. It fails with ErrInexact when the result is
+// not a whole number, with ErrOutOfRange when it does not fit a uint, and with ErrNotANumber for bad values.
+func (u Unit) Length(v Value) (uint, error) {
+	return u.convert(v, 1)
+}
+
+// Volume converts a volume in the cube of u to the cube of the canonical unit, see Length.
+func (u Unit) Volume(v Value) (uint, error) {
+	return u.convert(v, 3)
+}
+
+func (u Unit) convert(v Value, power int) (uint, error) {
+	if v.bad != "" {
+		return 0, fmt.Errorf("%w, got a %s", ErrNotANumber, v.bad)
+	}
+
+	if v.rat == nil {
+		return 0, nil
+	}
+
+	r := new(big.Rat).Mul(v.rat, u.factor(power))
+	if !r.IsInt() {
+		return 0, ErrInexact
+	}
+
+	n := r.Num()
+	if !n.IsUint64() || n.Uint64() > uint64(^uint(0)) {
+		return 0, ErrOutOfRange
+	}
+
+	return uint(n.Uint64()), nil
+}
+
+// RenderLength converts a length in the canonical unit to u. Lengths that are not a whole number
+// of u are rounded to the closest float64.
+func (u Unit) RenderLength(n uint) json.Number {
+	return u.render(n, 1)
+}
+
+// RenderVolume converts a volume in the cube of the canonical unit to the cube of u, see RenderLength.
+func (u Unit) RenderVolume(n uint) json.Number {
+	return u.render(n, 3)
+}
+
+func (u Unit) render(n uint, power int) json.Number {
+	if u == "" || u == Canonical {
+		return json.Number(strconv.FormatUint(uint64(n), 10))
+	}
+
+	r := new(big.Rat).SetUint64(uint64(n))
+	r.Quo(r, u.factor(power))
+	if r.IsInt() {
+		return json.Number(r.Num().String())
+	}
+
+	f, _ := r.Float64()
+
+	return json.Number(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+// jsonValueKind describes a JSON value the way encoding/json does in its errors.
+func jsonValueKind(b []byte) string {
+	if len(b) == 0 {
+		return "value"
+	}
+
+	switch b[0] {
+	case '"':
+		return "string"
+	case '{':
+		return "object"
+	case '[':
+		return "array"
+	case 't', 'f':
+		return "bool"
+	default:
+		return "number " + string(b)
+	}
+}
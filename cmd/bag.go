@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"log"
+	"strconv"
+
+	"cuboid-challenge/app/config"
+	"cuboid-challenge/app/db"
+	"cuboid-challenge/app/inventory"
+	"cuboid-challenge/app/store"
+
+	"github.com/spf13/cobra"
+)
+
+func bagCmd() *cobra.Command {
+	bag := &cobra.Command{
+		Use:   "bag",
+		Short: "Manage bags",
+	}
+
+	setDisabled := func(disabled bool) func(cmd *cobra.Command, args []string) {
+		return func(cmd *cobra.Command, args []string) {
+			id, err := strconv.ParseUint(args[0], 10, 64)
+			if err != nil {
+				log.Fatalf("Invalid bag id %q", args[0])
+			}
+
+			config.Load()
+			service := inventory.New(store.NewGorm(db.Connect()))
+			if _, err := service.UpdateBag(uint(id), nil, inventory.BagChange{Disabled: &disabled}); err != nil {
+				log.Fatalf("Could not update bag %d: %v", id, err)
+			}
+			log.Printf("Bag %d is now disabled=%t", id, disabled)
+		}
+	}
+
+	bag.AddCommand(&cobra.Command{
+		Use:   "disable <id>",
+		Short: "Disable a bag, it keeps its cuboids but takes no new one",
+		Args:  cobra.ExactArgs(1),
+		Run:   setDisabled(true),
+	}, &cobra.Command{
+		Use:   "enable <id>",
+		Short: "Enable a bag again",
+		Args:  cobra.ExactArgs(1),
+		Run:   setDisabled(false),
+	})
+
+	return bag
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"log"
+	"time"
+
+	"cuboid-challenge/app/config"
+	"cuboid-challenge/app/db"
+	"cuboid-challenge/app/store"
+
+	"github.com/spf13/cobra"
+)
+
+func purgeCmd() *cobra.Command {
+	var olderThan time.Duration
+
+	purge := &cobra.Command{
+		Use:   "purge",
+		Short: "Permanently remove soft deleted bags and cuboids, and expired idempotency keys",
+		Run: func(cmd *cobra.Command, args []string) {
+			config.Load()
+			purged, err := store.Purge(store.NewGorm(db.Connect()), olderThan, config.ENV.IdempotencyWindow)
+			if err != nil {
+				log.Fatalf("Could not purge: %v", err)
+			}
+			log.Printf("Purged %d bags, %d cuboids and %d idempotency keys", purged.Bags, purged.Cuboids, purged.IdempotencyKeys)
+		},
+	}
+
+	// no default, purging everything deleted up to now has to be asked for with --older-than 0s
+	purge.Flags().DurationVar(&olderThan, "older-than", 0, "only purge records deleted at least this long ago, like 720h")
+	if err := purge.MarkFlagRequired("older-than"); err != nil {
+		log.Fatalln(err.Error())
+	}
+
+	return purge
+}
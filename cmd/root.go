@@ -8,7 +8,7 @@ import (
 
 func Execute() {
 	root := rootCmd()
-	root.AddCommand(migrateCmd())
+	root.AddCommand(migrateCmd(), purgeCmd(), bagCmd())
 
 	if err := root.Execute(); err != nil {
 		log.Fatalln(err.Error())